@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+// Audit checks that stored files of the given modules, or of every stored
+// module, are present in the asset store and match their type.
+func (be *backend) Audit(ctx context.Context, ids []uint) error {
+	ids, err := be.moduleIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tFOLDER\tPROBLEM\tURL")
+
+	checked, broken := 0, 0
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		files, err := be.storage.Files().ListByModuleID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("list files: %w", err)
+		}
+
+		for _, f := range files {
+			mf := module.ModuleFile{URL: f.URL, Type: f.Type, Extension: f.Extension}
+			checked++
+
+			problem := be.auditFile(ctx, mf)
+			if problem == "" {
+				continue
+			}
+
+			broken++
+
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", id, mf.GetFolder(), problem, f.URL)
+		}
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	be.logger.Info("modules audited", uberzap.Int("modules", len(ids)), uberzap.Int("files", checked), uberzap.Int("broken", broken))
+
+	return nil
+}
+
+// auditFile returns the problem of the stored file, empty when there is none.
+func (be *backend) auditFile(ctx context.Context, mf module.ModuleFile) string {
+	info, ok := downloader.Lookup(ctx, be.assets, mf)
+	if !ok {
+		return "missing"
+	}
+
+	r, err := be.assets.Get(ctx, info.Key)
+	if err != nil {
+		return err.Error()
+	}
+	defer r.Close()
+
+	err = downloader.Validate(r, mf.Type)
+	if err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
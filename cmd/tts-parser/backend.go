@@ -1,20 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
 
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/assetstore"
 	"github.com/ldmonster/tts-parser/internal/downloader"
 	"github.com/ldmonster/tts-parser/internal/module"
 	"github.com/ldmonster/tts-parser/internal/storage/gorm"
-	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
+	"github.com/ldmonster/tts-parser/internal/storage/memory"
 
 	uberzap "go.uber.org/zap"
 	tele "gopkg.in/telebot.v3"
@@ -24,7 +28,11 @@ type backend struct {
 	cfg    *Config
 	logger *uberzap.Logger
 
-	storage *gorm.Storage
+	storage service.Storage
+	assets  assetstore.Store
+
+	// writeMu serializes writes of modules synced at once
+	writeMu sync.Mutex
 
 	bot *tele.Bot
 }
@@ -36,115 +44,375 @@ func NewBackend(cfg *Config, logger *uberzap.Logger) *backend {
 	}
 }
 
-func (be *backend) init() error {
-	err := be.initStorage()
+type initOptions struct {
+	// Migrate applies pending schema migrations.
+	Migrate bool
+}
+
+func (be *backend) init(opts initOptions) error {
+	err := be.initStorage(opts.Migrate)
 	if err != nil {
 		return fmt.Errorf("storage initialization: %w", err)
 	}
 
+	err = be.initAssets()
+	if err != nil {
+		return fmt.Errorf("asset store initialization: %w", err)
+	}
+
 	return nil
 }
 
-func (be *backend) initStorage() error {
+func (be *backend) initAssets() error {
+	switch be.cfg.Assets.Backend {
+	case AssetsBackendLocal, "":
+		be.assets = assetstore.NewLocal(be.cfg.Downloader.TempDir)
+	case AssetsBackendS3:
+		s3cfg := be.cfg.Assets.S3
+
+		store, err := assetstore.NewS3(context.Background(), assetstore.S3Options{
+			Endpoint:  s3cfg.Endpoint,
+			Bucket:    s3cfg.Bucket,
+			Region:    s3cfg.Region,
+			AccessKey: s3cfg.AccessKey,
+			SecretKey: s3cfg.SecretKey,
+			UseSSL:    s3cfg.UseSSL,
+			Prefix:    s3cfg.Prefix,
+		})
+		if err != nil {
+			return fmt.Errorf("creating s3 store: %w", err)
+		}
+
+		be.assets = store
+	default:
+		return fmt.Errorf("unknown assets backend %q", be.cfg.Assets.Backend)
+	}
+
+	return nil
+}
+
+func (be *backend) initStorage(migrate bool) error {
 	ctx := context.Background()
 
 	var err error
-	dbPath := filepath.Join(be.cfg.RootPath, be.cfg.Storage.SqliteDBPath)
-	be.storage, err = gorm.NewStorage(dbPath, be.logger.Named("storage"))
+
+	switch be.cfg.Storage.Driver {
+	case StorageDriverSQLite, "":
+		dbPath := filepath.Join(be.cfg.RootPath, be.cfg.Storage.SqliteDBPath)
+		be.storage, err = gorm.NewSQLiteStorage(dbPath, be.logger.Named("storage"))
+	case StorageDriverPostgres:
+		if be.cfg.Storage.PostgresDSN == "" {
+			return errors.New("postgres driver requires a dsn")
+		}
+
+		be.storage, err = gorm.NewPostgresStorage(be.cfg.Storage.PostgresDSN, be.logger.Named("storage"))
+	case StorageDriverMemory:
+		be.storage = memory.NewStorage()
+	default:
+		return fmt.Errorf("unknown storage driver %q", be.cfg.Storage.Driver)
+	}
+
 	if err != nil {
 		return fmt.Errorf("creating storage: %w", err)
 	}
 
-	err = be.storage.AutoMigrate(ctx)
+	if !migrate {
+		return nil
+	}
+
+	err = be.storage.Migrate(ctx)
 	if err != nil {
-		return fmt.Errorf("auto migration: %w", err)
+		return fmt.Errorf("migration: %w", err)
 	}
 
 	return nil
 }
 
+// moduleIDs returns ids as is, or every module stored in the DB when ids is empty.
+func (be *backend) moduleIDs(ctx context.Context, ids []uint) ([]uint, error) {
+	if len(ids) > 0 {
+		return ids, nil
+	}
+
+	mods, err := be.storage.Modules().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list modules: %w", err)
+	}
+
+	for _, m := range mods {
+		ids = append(ids, m.ID)
+	}
+
+	return ids, nil
+}
+
+func (be *backend) workshopFilePath(id uint) string {
+	return filepath.Join(be.cfg.TTS.WorkshopPath(), fmt.Sprintf("%d.json", id))
+}
+
 var jsonRegex = regexp.MustCompile(`^([0-9]*).json$`)
 
-func (be *backend) Start(ctx context.Context) {
-	dir := `E:\Tabletop Simulator\Mods\Workshop`
+var errRunDeadline = errors.New("run deadline exceeded")
+
+type startOptions struct {
+	// Full disables skipping of unchanged workshop files.
+	Full bool
+	// Install are TTS Mods directories files are installed into besides the configured ones.
+	Install []string
+	// InstallTTS installs files into the configured TTS Mods directory.
+	InstallTTS bool
+	// Modules limits the run to these module IDs, every workshop file is parsed when empty.
+	Modules []uint
+	// Command is recorded with the run.
+	Command string
+	// Report is a file the run is written to as JSON when set.
+	Report string
+	// Bandwidth replaces the configured bandwidth cap when set.
+	Bandwidth *int64
+}
+
+// Start parses workshop files and syncs changed modules. Once ctx is cancelled
+// or the run deadline passes no more modules are started, downloads in flight
+// get the configured shutdown timeout to finish and modules synced by then are
+// committed. A run stopped by its deadline returns errRunDeadline.
+func (be *backend) Start(ctx context.Context, opts startOptions) error {
+	dir := be.cfg.TTS.WorkshopPath()
+
+	dlOpts := be.cfg.Downloader.Options()
+
+	dlOpts.InstallPaths = append(slices.Clip(dlOpts.InstallPaths), opts.Install...)
+	if opts.InstallTTS {
+		dlOpts.InstallPaths = append(dlOpts.InstallPaths, be.cfg.TTS.ModsPath)
+	}
+
+	if opts.Bandwidth != nil {
+		dlOpts.Bandwidth = *opts.Bandwidth
+	}
+
+	// modules downloaded at once share host limits and the bandwidth
+	dlOpts.Scheduler = downloader.NewScheduler(dlOpts)
 
 	fs, err := os.ReadDir(dir)
 	if err != nil {
-		be.logger.Fatal("reading workshop directory", uberzap.Error(err))
+		return fmt.Errorf("reading workshop directory: %w", err)
+	}
+
+	scan, err := be.newWorkshopScan(ctx, dir, opts.Full)
+	if err != nil {
+		return fmt.Errorf("preparing workshop scan: %w", err)
+	}
+
+	run := &service.Run{
+		Command:   opts.Command,
+		Modules:   opts.Modules,
+		Status:    service.RunStatusRunning,
+		StartedAt: time.Now(),
+	}
+
+	err = be.storage.Runs().Create(ctx, run)
+	if err != nil {
+		return fmt.Errorf("recording run: %w", err)
+	}
+
+	if timeout := be.cfg.Downloader.Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	workCtx, stopWork := be.drainContext(ctx)
+	defer stopWork()
+
+	// selected modules are marked once their workshop file is found
+	selected := make(map[uint]bool, len(opts.Modules))
+	for _, id := range opts.Modules {
+		selected[id] = false
 	}
 
 	parsingWg := new(sync.WaitGroup)
 	throttleCh := make(chan struct{}, 10)
-	modulesCh := make(chan module.TTSModule, 100)
-	dbWritingDoneCh := make(chan struct{}, 1)
+	modulesCh := make(chan scannedModule, 100)
+	dbWritingDoneCh := make(chan struct{})
 
 	// Start DB writer goroutine
-	go be.processModules(ctx, modulesCh, dbWritingDoneCh)
+	go be.processModules(ctx, workCtx, run, dlOpts, modulesCh, dbWritingDoneCh)
+
+	seen := make([]uint, 0, len(fs))
 
 	// Parse workshop files
 	for _, f := range fs {
+		if ctx.Err() != nil {
+			break
+		}
+		if subs := jsonRegex.FindStringSubmatch(f.Name()); subs != nil {
+			if id, err := strconv.ParseUint(subs[1], 10, 0); err == nil {
+				seen = append(seen, uint(id))
+
+				if _, ok := selected[uint(id)]; ok {
+					selected[uint(id)] = true
+				} else if len(selected) > 0 {
+					continue
+				}
+			}
+		} else if len(selected) > 0 {
+			continue
+		}
+
 		parsingWg.Add(1)
 		throttleCh <- struct{}{}
 
-		go be.parseWorkshopFile(ctx, f, dir, parsingWg, throttleCh, modulesCh)
+		go be.parseWorkshopFile(workCtx, f, scan, parsingWg, throttleCh, modulesCh)
+	}
+
+	for _, id := range opts.Modules {
+		if !selected[id] {
+			be.logger.Warn("module is not found in the workshop directory", uberzap.Uint("id", id))
+		}
 	}
 
 	parsingWg.Wait()
-	be.logger.Info("parsing completed")
+	be.logger.Info("parsing completed", uberzap.Int64("parsed", scan.parsed.Load()), uberzap.Int64("skipped", scan.skipped.Load()))
 
 	close(modulesCh)
 	<-dbWritingDoneCh
+
+	// skipped modules have their files stored already, they are only installed
+	if len(dlOpts.InstallPaths) > 0 && len(scan.unchanged) > 0 && ctx.Err() == nil {
+		installed, failed, err := be.installModules(workCtx, scan.unchanged, dlOpts.InstallPaths)
+		if err != nil {
+			be.logger.Error("installing skipped modules", uberzap.Error(err))
+		}
+
+		be.logger.Info("skipped modules installed", uberzap.Int("modules", len(scan.unchanged)), uberzap.Int("installed", installed), uberzap.Int("failed", failed))
+	}
+
+	// a partial or interrupted run does not see every workshop file
+	if len(opts.Modules) == 0 && ctx.Err() == nil {
+		err = be.updateModuleStatuses(ctx, seen)
+		if err != nil {
+			be.logger.Error("updating module statuses", uberzap.Error(err))
+		}
+	}
+
+	run.Parsed = int(scan.parsed.Load())
+	run.Skipped = int(scan.skipped.Load())
+
+	err = be.finishRun(ctx, run, opts.Report)
+	if err != nil {
+		be.logger.Error("recording run", uberzap.Uint("run", run.ID), uberzap.Error(err))
+	}
+
+	be.removeTempFiles()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errRunDeadline
+	}
+
+	return nil
 }
 
-func (be *backend) processModules(ctx context.Context, modulesCh chan module.TTSModule, dbWritingDoneCh chan<- struct{}) {
-	for {
+// drainContext returns a context cancelled the shutdown timeout after ctx is.
+func (be *backend) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	workCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	go func() {
 		select {
-		case mod, ok := <-modulesCh:
-			{
-				if !ok {
-					dbWritingDoneCh <- struct{}{}
-				}
+		case <-ctx.Done():
+		case <-workCtx.Done():
+			return
+		}
 
-				files, err := be.storage.File.ListByModuleID(ctx, mod.ID)
-				if err != nil {
-					panic(err)
-				}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			be.logger.Warn("run deadline exceeded", uberzap.Duration("timeout", be.cfg.Downloader.Timeout))
+		}
 
-				orphans := mod.MergeFiles(model.RemapToServiceFiles(files...))
-				if len(orphans) > 0 {
-					be.logger.Warn("orphans", uberzap.Any("orphans", orphans))
-					err := be.storage.File.DeleteByModuleID(ctx, orphans[0].ModuleID)
-					if err != nil {
-						panic(err)
-					}
-				}
+		be.logger.Info("waiting for downloads in flight", uberzap.Duration("timeout", be.cfg.Downloader.ShutdownTimeout))
 
-				c := downloader.NewClient(be.logger)
-				dm := c.DownloadModule(ctx, &mod)
+		timer := time.NewTimer(be.cfg.Downloader.ShutdownTimeout)
+		defer timer.Stop()
 
-				// TODO: count errors (origin len - downloaded len)
-				be.logger.Info("module downloaded", uberzap.String("module", mod.Name), uberzap.Uint("id", mod.ID), uberzap.Int("count", len(dm)))
+		select {
+		case <-timer.C:
+			be.logger.Warn("shutdown timeout exceeded, cancelling downloads")
+			cancel()
+		case <-workCtx.Done():
+		}
+	}()
+
+	return workCtx, cancel
+}
 
-				err = be.storage.File.BatchCreate(ctx, model.RemapFromServiceFiles(dm...)...)
-				if err != nil {
-					panic(err)
+// processModules syncs modules until modulesCh is closed, the configured
+// number of them at once. Modules received after ctx is cancelled are left for
+// the next run.
+func (be *backend) processModules(ctx, workCtx context.Context, run *service.Run, dlOpts downloader.Options, modulesCh <-chan scannedModule, dbWritingDoneCh chan<- struct{}) {
+	defer close(dbWritingDoneCh)
+
+	wg := new(sync.WaitGroup)
+
+	for range be.cfg.Downloader.ModuleConcurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for mod := range modulesCh {
+				if ctx.Err() != nil {
+					continue
 				}
 
-				be.storage.Module.Create(ctx, &model.Module{
-					ID:            mod.ID,
-					Name:          mod.Name,
-					EpochTime:     mod.EpochTime,
-					VersionNumber: mod.VersionNumber.Original(),
-				})
+				result := be.processModule(workCtx, dlOpts, &mod)
+
+				// results of modules cancelled by the shutdown timeout are recorded as well
+				be.writeMu.Lock()
+				be.recordModule(context.WithoutCancel(workCtx), run, result)
+				be.writeMu.Unlock()
 			}
-		case <-ctx.Done():
-			fmt.Println("stopped")
-			os.Exit(0)
-		}
+		}()
 	}
+
+	wg.Wait()
 }
 
-func (be *backend) parseWorkshopFile(ctx context.Context, file os.DirEntry, dir string, parsingWg *sync.WaitGroup, throttleCh chan struct{}, modulesCh chan<- module.TTSModule) {
+// removeTempFiles removes partial files left in the asset store by a killed run.
+func (be *backend) removeTempFiles() {
+	remover, ok := be.assets.(assetstore.TempRemover)
+	if !ok {
+		return
+	}
+
+	n, err := remover.RemoveTemp()
+	if err != nil {
+		be.logger.Warn("removing temporary files", uberzap.Error(err))
+	}
+
+	if n > 0 {
+		be.logger.Info("temporary files removed", uberzap.Int("files", n))
+	}
+}
+
+// processModule syncs the scanned module, modules which failed to parse are
+// only reported.
+func (be *backend) processModule(ctx context.Context, dlOpts downloader.Options, mod *scannedModule) service.RunModule {
+	started := time.Now()
+
+	if mod.Err != nil {
+		return service.RunModule{ModuleID: mod.ID, Error: mod.Err.Error()}
+	}
+
+	result, err := be.syncModule(ctx, dlOpts, mod)
+	if err != nil {
+		be.logger.Error("sync module", uberzap.Uint("id", mod.ID), uberzap.Error(err))
+		result.Error = err.Error()
+	}
+
+	result.Duration = time.Since(started)
+
+	return result
+}
+
+func (be *backend) parseWorkshopFile(ctx context.Context, file os.DirEntry, scan *workshopScan, parsingWg *sync.WaitGroup, throttleCh chan struct{}, modulesCh chan<- scannedModule) {
 	defer func() {
 		parsingWg.Done()
 		<-throttleCh
@@ -158,34 +426,59 @@ func (be *backend) parseWorkshopFile(ctx context.Context, file os.DirEntry, dir
 
 	id, err := strconv.Atoi(subs[0][1])
 	if err != nil {
-		panic(err)
+		return
+	}
+
+	fail := func(err error) {
+		be.logger.Error("parse workshop file", uberzap.String("file", file.Name()), uberzap.Error(err))
+
+		failed := scannedModule{Err: err}
+		failed.ID = uint(id)
+
+		modulesCh <- failed
 	}
 
-	f, err := os.OpenFile(filepath.Join(dir, file.Name()), os.O_RDONLY, 0o666)
+	src, data, changed, err := scan.check(ctx, uint(id), file)
 	if err != nil {
-		panic(err)
+		fail(fmt.Errorf("check file: %w", err))
+		return
+	}
+
+	if !changed {
+		return
 	}
-	defer f.Close()
 
-	mod := new(module.Module)
-	err = json.NewDecoder(f).Decode(mod)
+	mod, err := module.Decode(bytes.NewReader(data))
 	if err != nil {
-		panic(err)
+		fail(err)
+		return
 	}
 
 	timestamp, err := time.Parse("1/2/2006 15:04:05 PM", mod.Date)
 	if err != nil {
 		timestamp, err = time.Parse("01/02/2006 15:04:05", mod.Date)
 		if err != nil {
-			panic(err)
+			fail(fmt.Errorf("parse date: %w", err))
+			return
 		}
 	}
 
+	version, err := newModuleVersion(uint(id), data, mod)
+	if err != nil {
+		fail(fmt.Errorf("archive version: %w", err))
+		return
+	}
+
 	result := module.NewTTSModule()
 	result.ScanModule(mod)
 
 	result.ID = uint(id)
 	result.EpochTime = uint(timestamp.Unix())
 
-	modulesCh <- *result
+	modulesCh <- scannedModule{
+		TTSModule: *result,
+		Source:    src,
+		Version:   version,
+		Documents: mod.SearchDocuments(uint(id)),
+	}
 }
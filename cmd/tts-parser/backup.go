@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+// Backup copies the workshop JSON and stored files of the given modules, or of
+// every stored module, into output laid out like the TTS Mods directory.
+// Files already in output are kept.
+func (be *backend) Backup(ctx context.Context, ids []uint, output string) error {
+	ids, err := be.moduleIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	copied, missing := 0, 0
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		err = be.backupSave(ctx, id, filepath.Join(output, "Workshop", fmt.Sprintf("%d.json", id)))
+		if err != nil {
+			be.logger.Warn("backing up save", uberzap.Uint("id", id), uberzap.Error(err))
+		}
+
+		files, err := be.storage.Files().ListByModuleID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("list files: %w", err)
+		}
+
+		for _, f := range files {
+			mf := module.ModuleFile{URL: f.URL, Type: f.Type, Extension: f.Extension}
+
+			info, ok := downloader.Lookup(ctx, be.assets, mf)
+			if !ok {
+				missing++
+				continue
+			}
+
+			done, err := downloader.Export(ctx, be.assets, info.Key, downloader.InstallPath(output, mf), false)
+			if err != nil {
+				return fmt.Errorf("backing up %s: %w", f.URL, err)
+			}
+
+			if done {
+				copied++
+			}
+		}
+	}
+
+	be.logger.Info("modules backed up",
+		uberzap.Int("modules", len(ids)),
+		uberzap.String("output", output),
+		uberzap.Int("copied", copied),
+		uberzap.Int("missing", missing),
+	)
+
+	return nil
+}
+
+// backupSave writes the workshop JSON, or the latest archived version of a
+// module no longer in the Workshop folder.
+func (be *backend) backupSave(ctx context.Context, id uint, target string) error {
+	src := be.workshopFilePath(id)
+
+	_, err := os.Stat(src)
+	if err == nil {
+		_, err = downloader.CopyFile(src, target, false)
+		return err
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	v, err := be.findModuleVersion(ctx, id, "latest")
+	if err != nil {
+		return err
+	}
+
+	data, err := gunzipBytes(v.Data)
+	if err != nil {
+		return fmt.Errorf("decompress version: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(target), 0o777)
+	if err != nil {
+		return fmt.Errorf("creating directories: %w", err)
+	}
+
+	return os.WriteFile(target, data, 0o666)
+}
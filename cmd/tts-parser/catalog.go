@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/module"
+	"github.com/ldmonster/tts-parser/internal/script"
+
+	uberzap "go.uber.org/zap"
+)
+
+// CatalogScripts indexes scripts of the given modules, or of every module in the DB:
+// identical scripts are stored once, bundles are unpacked into their modules
+// and every usage is linked to its object.
+func (be *backend) CatalogScripts(ctx context.Context, ids []uint) error {
+	ids, err := be.moduleIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	all := script.NewCatalog()
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		mod, err := module.DecodeFile(be.workshopFilePath(id))
+		if errors.Is(err, os.ErrNotExist) {
+			be.logger.Warn("workshop file is not found", uberzap.Uint("id", id))
+			continue
+		}
+
+		if err != nil {
+			be.logger.Warn("reading workshop module", uberzap.Uint("id", id), uberzap.Error(err))
+			continue
+		}
+
+		catalog := catalogModuleScripts(mod)
+
+		err = be.storeScriptCatalog(ctx, id, catalog)
+		if err != nil {
+			return fmt.Errorf("module %d: %w", id, err)
+		}
+
+		all.Merge(catalog)
+	}
+
+	entries := slices.DeleteFunc(all.List(), func(e *script.Entry) bool {
+		return len(e.Usages) == 0
+	})
+
+	slices.SortStableFunc(entries, func(a, b *script.Entry) int {
+		return len(b.Usages) - len(a.Usages)
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HASH\tSIZE\tUSES\tINCLUDES\tFIRST USE")
+
+	for _, e := range entries {
+		first := e.Usages[0]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s %s\n", e.Hash[:12], len(e.Content), len(e.Usages), len(e.Includes), first.GUID, first.Nickname)
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	be.logger.Info("scripts cataloged", uberzap.Int("modules", len(ids)), uberzap.Int("scripts", len(all.Entries)))
+
+	return nil
+}
+
+func catalogModuleScripts(mod *module.Module) *script.Catalog {
+	catalog := script.NewCatalog()
+
+	if mod.LuaScript != "" {
+		catalog.Add(mod.LuaScript, script.Usage{Path: globalScriptPath})
+	}
+
+	mod.WalkObjects(func(path module.ObjectPath, obj *module.Object) {
+		if obj.LuaScript == "" {
+			return
+		}
+
+		catalog.Add(obj.LuaScript, script.Usage{
+			GUID:     obj.GUID,
+			Nickname: obj.Nickname,
+			Path:     path.String(),
+		})
+	})
+
+	return catalog
+}
+
+func (be *backend) storeScriptCatalog(ctx context.Context, moduleID uint, catalog *script.Catalog) error {
+	entries := catalog.List()
+
+	scripts := make([]*service.Script, 0, len(entries))
+	for _, e := range entries {
+		scripts = append(scripts, &service.Script{
+			Hash:    e.Hash,
+			Size:    len(e.Content),
+			Content: e.Content,
+		})
+	}
+
+	return be.storage.Transaction(ctx, func(ctx context.Context) error {
+		err := be.storage.Scripts().Upsert(ctx, scripts...)
+		if err != nil {
+			return fmt.Errorf("upsert scripts: %w", err)
+		}
+
+		idByHash := make(map[string]uint, len(scripts))
+		for _, sc := range scripts {
+			idByHash[sc.Hash] = sc.ID
+		}
+
+		usages := make([]service.ScriptUsage, 0, len(entries))
+		includes := make([]service.ScriptInclude, 0)
+
+		for _, e := range entries {
+			for _, u := range e.Usages {
+				usages = append(usages, service.ScriptUsage{
+					ScriptID: idByHash[e.Hash],
+					ModuleID: moduleID,
+					GUID:     u.GUID,
+					Nickname: u.Nickname,
+					Path:     u.Path,
+				})
+			}
+
+			for _, inc := range e.Includes {
+				includes = append(includes, service.ScriptInclude{
+					BundleID: idByHash[e.Hash],
+					Name:     inc.Name,
+					ScriptID: idByHash[inc.Hash],
+				})
+			}
+		}
+
+		err = be.storage.Scripts().BatchCreateIncludes(ctx, includes...)
+		if err != nil {
+			return fmt.Errorf("create includes: %w", err)
+		}
+
+		err = be.storage.Scripts().ReplaceUsages(ctx, moduleID, usages...)
+		if err != nil {
+			return fmt.Errorf("replace usages: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// SearchScripts prints objects whose scripts, or bundled modules, contain text.
+func (be *backend) SearchScripts(ctx context.Context, text string) error {
+	matches, err := be.storage.Scripts().Search(ctx, text)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tGUID\tNICKNAME\tPATH\tHASH\tSIZE")
+
+	for _, m := range matches {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\n", m.ModuleID, m.GUID, m.Nickname, m.Path, m.Hash[:12], m.Size)
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	return nil
+}
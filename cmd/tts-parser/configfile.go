@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// movedKeys are settings of earlier config files, keyed by their old name.
+var movedKeys = map[string]string{
+	"loglevelraw":          "log.level",
+	"storage.sqlitedbpath": "storage.sqlite_db_path",
+	"storage.postgresdsn":  "storage.postgres_dsn",
+}
+
+// decodeFile decodes the config file over the config. Keys not matching a
+// setting are errors, they are reported together with their line.
+func (cfg *Config) decodeFile() error {
+	if cfg.ConfigPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(cfg.ConfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	var doc yaml.Node
+
+	err = yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", cfg.ConfigPath, err)
+	}
+
+	// an empty file or one of comments only
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	errs := checkKeys(doc.Content[0], reflect.TypeOf(cfg), "", cfg.fileKeys)
+	if len(errs) > 0 {
+		return fmt.Errorf("config file %s:\n%w", cfg.ConfigPath, errors.Join(errs...))
+	}
+
+	err = doc.Decode(cfg)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", cfg.ConfigPath, err)
+	}
+
+	return nil
+}
+
+// checkKeys compares keys of the node with yaml names of fields of t and
+// records the found ones in seen. Sections left empty, e.g. with every setting
+// commented out, are dropped so they do not reset the section.
+func checkKeys(node *yaml.Node, t reflect.Type, path string, seen map[string]bool) []error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var errs []error
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			if isNull(node) {
+				return nil
+			}
+
+			return []error{fmt.Errorf("line %d: %s must be a mapping", node.Line, displayKey(path))}
+		}
+
+		fields := yamlFields(t)
+		content := node.Content[:0]
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			key := joinKey(path, k.Value)
+
+			f, ok := fields[k.Value]
+			if !ok {
+				errs = append(errs, unknownKeyError(k.Line, key, path, fields))
+
+				continue
+			}
+
+			if isNull(v) && isSection(f.Type) {
+				continue
+			}
+
+			seen[key] = true
+			errs = append(errs, checkKeys(v, f.Type, key, seen)...)
+			content = append(content, k, v)
+		}
+
+		node.Content = content
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode || !isSection(t.Elem()) {
+			return nil
+		}
+
+		for i, item := range node.Content {
+			errs = append(errs, checkKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), seen)...)
+		}
+	}
+
+	return errs
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// isSection reports whether t is decoded from a mapping of settings.
+func isSection(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+// yamlFields returns exported fields of the struct by their yaml name.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+
+	for _, f := range reflect.VisibleFields(t) {
+		name, ok := yamlName(f)
+		if ok {
+			fields[name] = f
+		}
+	}
+
+	return fields
+}
+
+func yamlName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return strings.ToLower(f.Name), true
+	default:
+		return name, true
+	}
+}
+
+func unknownKeyError(line int, key, section string, fields map[string]reflect.StructField) error {
+	if moved, ok := movedKeys[key]; ok {
+		return fmt.Errorf("line %d: unknown setting %q, it was renamed to %q", line, key, moved)
+	}
+
+	name := key[strings.LastIndex(key, ".")+1:]
+
+	if suggestion := closestName(name, fields); suggestion != "" {
+		return fmt.Errorf("line %d: unknown setting %q, did you mean %q?", line, key, joinKey(section, suggestion))
+	}
+
+	names := make([]string, 0, len(fields))
+	for n := range fields {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	return fmt.Errorf("line %d: unknown setting %q, %s has %s", line, key, displayKey(section), strings.Join(names, ", "))
+}
+
+// closestName returns the field name within a few edits of name, if any.
+func closestName(name string, fields map[string]reflect.StructField) string {
+	best, bestDistance := "", max(2, len(name)/3)+1
+
+	for candidate := range fields {
+		d := editDistance(strings.ToLower(name), candidate)
+		if d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
+
+// editDistance is the Levenshtein distance of a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func joinKey(section, name string) string {
+	if section == "" {
+		return name
+	}
+
+	return section + "." + name
+}
+
+func displayKey(path string) string {
+	if path == "" {
+		return "the config"
+	}
+
+	return path
+}
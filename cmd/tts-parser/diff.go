@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ldmonster/tts-parser/internal/module"
+	"github.com/ldmonster/tts-parser/internal/script"
+)
+
+// currentVersion refers to the workshop file as it is on disk.
+const currentVersion = "current"
+
+type diffSide struct {
+	Label string
+	Hash  string
+	Mod   *module.Module
+}
+
+// Diff compares two versions of the module. Without versions the current
+// workshop file is compared with the latest archived version, or the two latest
+// archived versions are compared when the workshop file is archived already.
+func (be *backend) Diff(ctx context.Context, id uint, oldVersion, newVersion string) error {
+	if oldVersion == "" {
+		var err error
+
+		oldVersion, newVersion, err = be.defaultDiffVersions(ctx, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	old, err := be.loadDiffSide(ctx, id, oldVersion)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", oldVersion, err)
+	}
+
+	new, err := be.loadDiffSide(ctx, id, newVersion)
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", newVersion, err)
+	}
+
+	writeModuleDiff(os.Stdout, old, new, module.Diff(old.Mod, new.Mod))
+
+	return nil
+}
+
+func (be *backend) defaultDiffVersions(ctx context.Context, id uint) (string, string, error) {
+	versions, err := be.storage.ModuleVersions().ListByModuleID(ctx, id)
+	if err != nil {
+		return "", "", fmt.Errorf("list versions: %w", err)
+	}
+
+	if len(versions) == 0 {
+		return "", "", fmt.Errorf("module %d has no archived versions", id)
+	}
+
+	latest := versions[len(versions)-1]
+
+	current, err := be.loadDiffSide(ctx, id, currentVersion)
+	if err == nil && current.Hash != latest.Hash {
+		return strconv.Itoa(len(versions)), currentVersion, nil
+	}
+
+	if len(versions) < 2 {
+		return "", "", fmt.Errorf("module %d has a single version", id)
+	}
+
+	return strconv.Itoa(len(versions) - 1), strconv.Itoa(len(versions)), nil
+}
+
+func (be *backend) loadDiffSide(ctx context.Context, id uint, version string) (*diffSide, error) {
+	var (
+		data  []byte
+		label string
+	)
+
+	if version == currentVersion {
+		var err error
+
+		data, err = os.ReadFile(be.workshopFilePath(id))
+		if err != nil {
+			return nil, fmt.Errorf("read workshop file: %w", err)
+		}
+
+		label = currentVersion
+	} else {
+		v, err := be.findModuleVersion(ctx, id, version)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = gunzipBytes(v.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decompress version: %w", err)
+		}
+
+		label = v.Hash[:12]
+	}
+
+	mod, err := module.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return &diffSide{
+		Label: label,
+		Hash:  hex.EncodeToString(sum[:]),
+		Mod:   mod,
+	}, nil
+}
+
+func writeModuleDiff(w io.Writer, old, new *diffSide, d *module.ModuleDiff) {
+	fmt.Fprintf(w, "%s (%s, %s) -> %s (%s, %s)\n",
+		old.Label, old.Mod.SaveName, old.Mod.VersionNumber,
+		new.Label, new.Mod.SaveName, new.Mod.VersionNumber)
+
+	if d.IsEmpty() {
+		fmt.Fprintln(w, "no changes")
+		return
+	}
+
+	if len(d.Added) > 0 {
+		fmt.Fprintf(w, "\nAdded objects (%d):\n", len(d.Added))
+
+		for _, o := range d.Added {
+			fmt.Fprintf(w, "  + %s\n", o)
+		}
+	}
+
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(w, "\nRemoved objects (%d):\n", len(d.Removed))
+
+		for _, o := range d.Removed {
+			fmt.Fprintf(w, "  - %s\n", o)
+		}
+	}
+
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(w, "\nChanged objects (%d):\n", len(d.Changed))
+
+		for _, c := range d.Changed {
+			fmt.Fprintf(w, "  ~ %s\n", c.New)
+
+			if c.Moved {
+				if c.Old.Location != c.New.Location {
+					fmt.Fprintf(w, "      moved: %s -> %s\n", c.Old.Location, c.New.Location)
+				} else {
+					fmt.Fprintf(w, "      moved: (%.2f, %.2f, %.2f) -> (%.2f, %.2f, %.2f)\n",
+						c.Old.Position.X, c.Old.Position.Y, c.Old.Position.Z,
+						c.New.Position.X, c.New.Position.Y, c.New.Position.Z)
+				}
+			}
+
+			if c.NicknameChanged {
+				fmt.Fprintf(w, "      nickname: %q -> %q\n", c.Old.Nickname, c.New.Nickname)
+			}
+
+			if c.DescriptionChanged {
+				fmt.Fprintf(w, "      description: %q -> %q\n", c.OldDescription, c.NewDescription)
+			}
+		}
+	}
+
+	if len(d.Assets) > 0 {
+		fmt.Fprintln(w, "\nAssets:")
+
+		for _, a := range d.Assets {
+			folder := module.ModuleFile{Type: a.Type}.GetFolder()
+
+			for _, u := range a.Added {
+				fmt.Fprintf(w, "  + %s %s\n", folder, u)
+			}
+
+			for _, u := range a.Removed {
+				fmt.Fprintf(w, "  - %s %s\n", folder, u)
+			}
+		}
+	}
+
+	if d.GlobalScriptChanged {
+		fmt.Fprintln(w)
+		fmt.Fprint(w, script.UnifiedDiff(old.Label+"/"+globalScriptPath, new.Label+"/"+globalScriptPath, d.OldGlobalScript, d.NewGlobalScript))
+	}
+
+	for _, c := range d.Changed {
+		if !c.ScriptChanged {
+			continue
+		}
+
+		name := c.New.GUID + ".lua"
+
+		fmt.Fprintln(w)
+		fmt.Fprint(w, script.UnifiedDiff(old.Label+"/"+name, new.Label+"/"+name, c.OldScript, c.NewScript))
+	}
+}
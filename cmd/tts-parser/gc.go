@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+type gcOptions struct {
+	// Apply deletes unreferenced files and stale rows instead of listing them.
+	Apply bool
+}
+
+// GC removes cached files no module references. The live set joins stored
+// file rows with the files referenced by the workshop JSON of every active
+// module and the latest archived version of every removed one, so files shared
+// between modules stay while any of them uses it. Purged modules do not count.
+func (be *backend) GC(ctx context.Context, opts gcOptions) error {
+	live, err := be.gcLiveSet(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		unreferenced []string
+		reclaimable  int64
+	)
+
+	for t := service.FileType(service.FileTypeAsset); t < service.FileTypeOverall; t++ {
+		folder := module.ModuleFile{Type: t}.GetFolder()
+
+		infos, err := be.assets.List(ctx, folder+"/")
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", folder, err)
+		}
+
+		for _, info := range infos {
+			if _, ok := live[gcKey(info.Key)]; ok {
+				continue
+			}
+
+			unreferenced = append(unreferenced, info.Key)
+			reclaimable += info.Size
+		}
+	}
+
+	stale, err := be.gcStaleFiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range unreferenced {
+		fmt.Println(key)
+	}
+
+	for _, f := range stale {
+		fmt.Printf("missing file of module %d: %s\n", f.ModuleID, f.URL)
+	}
+
+	be.logger.Info("garbage collected",
+		uberzap.Bool("applied", opts.Apply),
+		uberzap.Int("unreferenced", len(unreferenced)),
+		uberzap.String("reclaimable", formatBytes(reclaimable)),
+		uberzap.Int("stale_rows", len(stale)),
+	)
+
+	if !opts.Apply {
+		return nil
+	}
+
+	for _, key := range unreferenced {
+		err = be.assets.Delete(ctx, key)
+		if err != nil {
+			return fmt.Errorf("removing %s: %w", key, err)
+		}
+	}
+
+	return be.storage.Transaction(ctx, func(ctx context.Context) error {
+		ids := make([]uint, 0, len(stale))
+		missing := make(map[uint]int)
+
+		for _, f := range stale {
+			ids = append(ids, f.ID)
+			missing[f.ModuleID]++
+		}
+
+		err := be.storage.Files().DeleteByIDs(ctx, ids...)
+		if err != nil {
+			return fmt.Errorf("delete stale files: %w", err)
+		}
+
+		// the files count as failed, the next download syncs the modules again
+		// instead of skipping their unchanged workshop files
+		for id, n := range missing {
+			m, err := be.storage.Modules().Get(ctx, id)
+			if err != nil {
+				return fmt.Errorf("get module %d: %w", id, err)
+			}
+
+			err = be.storage.Modules().Update(ctx, &service.Module{ID: id, FailedFiles: m.FailedFiles + n})
+			if err != nil {
+				return fmt.Errorf("update module %d: %w", id, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// gcKey identifies a stored file regardless of the image extension detected on download.
+func gcKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key))
+}
+
+func (be *backend) gcLiveSet(ctx context.Context) (map[string]struct{}, error) {
+	live := make(map[string]struct{})
+
+	add := func(mf module.ModuleFile) {
+		live[gcKey(downloader.Key(mf))] = struct{}{}
+	}
+
+	files, err := be.storage.Files().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+
+	for _, f := range files {
+		add(module.ModuleFile{URL: f.URL, Type: f.Type, Extension: f.Extension})
+	}
+
+	mods, err := be.storage.Modules().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list modules: %w", err)
+	}
+
+	for _, m := range mods {
+		var save *module.Module
+
+		switch m.Status {
+		case service.ModuleStatusPurged:
+			continue
+		case service.ModuleStatusRemoved:
+			save, err = be.latestArchivedSave(ctx, m.ID)
+		default:
+			save, err = module.DecodeFile(be.workshopFilePath(m.ID))
+		}
+
+		if err != nil {
+			// stored rows still protect files of the module
+			be.logger.Warn("reading module", uberzap.Uint("id", m.ID), uberzap.Error(err))
+			continue
+		}
+
+		scanned := module.NewTTSModule()
+		scanned.ScanModule(save)
+
+		for _, mf := range scanned.GetAll() {
+			add(mf)
+		}
+	}
+
+	return live, nil
+}
+
+func (be *backend) latestArchivedSave(ctx context.Context, id uint) (*module.Module, error) {
+	v, err := be.findModuleVersion(ctx, id, "latest")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := gunzipBytes(v.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decompress version: %w", err)
+	}
+
+	return module.Decode(bytes.NewReader(data))
+}
+
+// gcStaleFiles returns stored file rows whose file is absent from the store.
+func (be *backend) gcStaleFiles(ctx context.Context) ([]service.File, error) {
+	files, err := be.storage.Files().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+
+	stale := make([]service.File, 0)
+
+	for _, f := range files {
+		_, ok := downloader.Lookup(ctx, be.assets, module.ModuleFile{URL: f.URL, Type: f.Type, Extension: f.Extension})
+		if !ok {
+			stale = append(stale, f)
+		}
+	}
+
+	return stale, nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+type rollbackTarget string
+
+const (
+	rollbackTargetWorkshop rollbackTarget = "workshop"
+	rollbackTargetSaves    rollbackTarget = "saves"
+)
+
+type rollbackOptions struct {
+	Target rollbackTarget
+	Force  bool
+}
+
+// newModuleVersion prepares an archive entry for the workshop JSON of the module.
+func newModuleVersion(id uint, data []byte, mod *module.Module) (*service.ModuleVersion, error) {
+	compressed, err := gzipBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	return &service.ModuleVersion{
+		ModuleID:      id,
+		Hash:          hex.EncodeToString(sum[:]),
+		SaveName:      mod.SaveName,
+		VersionNumber: mod.VersionNumber,
+		EpochTime:     uint(saveTimestamp(mod).Unix()),
+		SaveEpochTime: saveEpochTime(mod),
+		Size:          int64(len(data)),
+		Data:          compressed,
+	}, nil
+}
+
+// saveTimestamp parses the save Date, falling back to EpochTime.
+func saveTimestamp(mod *module.Module) time.Time {
+	timestamp, err := time.Parse("1/2/2006 15:04:05 PM", mod.Date)
+	if err == nil {
+		return timestamp
+	}
+
+	timestamp, err = time.Parse("01/02/2006 15:04:05", mod.Date)
+	if err == nil {
+		return timestamp
+	}
+
+	return time.Unix(int64(saveEpochTime(mod)), 0)
+}
+
+// saveEpochTime returns the EpochTime of the save, zero when it is null.
+func saveEpochTime(mod *module.Module) int {
+	if mod.EpochTime == nil {
+		return 0
+	}
+
+	return *mod.EpochTime
+}
+
+// History prints archived versions of the module, oldest first.
+func (be *backend) History(ctx context.Context, id uint) error {
+	versions, err := be.storage.ModuleVersions().ListByModuleID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("list versions: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tHASH\tARCHIVED\tSAVED\tVERSION NUMBER\tSIZE\tSAVE NAME")
+
+	for i, v := range versions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			i+1,
+			v.Hash[:12],
+			v.CreatedAt.Local().Format(time.DateTime),
+			time.Unix(int64(v.EpochTime), 0).UTC().Format(time.DateTime),
+			v.VersionNumber,
+			v.Size,
+			v.SaveName,
+		)
+	}
+
+	return w.Flush()
+}
+
+// findModuleVersion resolves a version given as its number in History or as a hash prefix.
+func (be *backend) findModuleVersion(ctx context.Context, id uint, version string) (*service.ModuleVersion, error) {
+	versions, err := be.storage.ModuleVersions().ListByModuleID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list versions: %w", err)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("module %d: %w", id, service.ErrModuleVersionIsNotFound)
+	}
+
+	var found *service.ModuleVersion
+
+	switch n, err := strconv.Atoi(version); {
+	case version == "latest":
+		found = &versions[len(versions)-1]
+	case err == nil && n >= 1 && n <= len(versions):
+		found = &versions[n-1]
+	default:
+		for i := range versions {
+			if strings.HasPrefix(versions[i].Hash, strings.ToLower(version)) {
+				found = &versions[i]
+				break
+			}
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("module %d version %q: %w", id, version, service.ErrModuleVersionIsNotFound)
+	}
+
+	return be.storage.ModuleVersions().Get(ctx, found.ID)
+}
+
+// Rollback writes an archived version back into the Workshop or Saves folder.
+// The current workshop file is archived first so that it can be restored later.
+func (be *backend) Rollback(ctx context.Context, id uint, version string, opts rollbackOptions) error {
+	v, err := be.findModuleVersion(ctx, id, version)
+	if err != nil {
+		return err
+	}
+
+	data, err := gunzipBytes(v.Data)
+	if err != nil {
+		return fmt.Errorf("decompress version: %w", err)
+	}
+
+	var target string
+
+	switch opts.Target {
+	case rollbackTargetWorkshop, "":
+		target = be.workshopFilePath(id)
+
+		err = be.archiveWorkshopFile(ctx, id)
+		if err != nil {
+			return fmt.Errorf("archiving current workshop file: %w", err)
+		}
+	case rollbackTargetSaves:
+		target = filepath.Join(be.cfg.TTS.SavesPath, fmt.Sprintf("%d_%s.json", id, v.Hash[:12]))
+
+		if _, err := os.Stat(target); err == nil && !opts.Force {
+			return fmt.Errorf("%s: %w", target, ErrSaveExists)
+		}
+	default:
+		return fmt.Errorf("unknown rollback target %q", opts.Target)
+	}
+
+	err = os.MkdirAll(filepath.Dir(target), 0o777)
+	if err != nil {
+		return fmt.Errorf("creating directories: %w", err)
+	}
+
+	err = os.WriteFile(target, data, 0o666)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", target, err)
+	}
+
+	be.logger.Info("module version restored", uberzap.Uint("id", id), uberzap.String("hash", v.Hash), uberzap.String("path", target))
+
+	return nil
+}
+
+// archiveWorkshopFile stores the current workshop JSON of the module unless it is archived already.
+func (be *backend) archiveWorkshopFile(ctx context.Context, id uint) error {
+	data, err := os.ReadFile(be.workshopFilePath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	mod, err := module.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	v, err := newModuleVersion(id, data, mod)
+	if err != nil {
+		return err
+	}
+
+	return be.storage.ModuleVersions().Create(ctx, v)
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)/4))
+
+	w, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gunzipBytes(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+type importCacheOptions struct {
+	// From is the TTS Mods directory, the configured one when empty.
+	From string
+	// Link hardlinks files instead of copying them when possible.
+	Link bool
+}
+
+type importCacheStats struct {
+	Imported int
+	Existing int
+	Invalid  int
+	Raw      int
+	NotFound int
+}
+
+// rawFolders are TTS caches of processed images and models, kept next to the
+// originals. They are not valid image or model files.
+var rawFolders = map[service.FileType]struct {
+	Folder    string
+	Extension string
+}{
+	service.FileTypeImage: {Folder: "Images Raw", Extension: ".rawt"},
+	service.FileTypeModel: {Folder: "Models Raw", Extension: ".rawm"},
+}
+
+// ImportCache adopts files of the given modules, or of every stored module,
+// from an existing TTS Mods directory and registers them so they are never
+// downloaded again.
+func (be *backend) ImportCache(ctx context.Context, ids []uint, opts importCacheOptions) error {
+	ids, err := be.moduleIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	from := opts.From
+	if from == "" {
+		from = be.cfg.TTS.ModsPath
+	}
+
+	total := importCacheStats{}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		stats, err := be.importModuleCache(ctx, id, from, opts)
+		if err != nil {
+			be.logger.Warn("importing module cache", uberzap.Uint("id", id), uberzap.Error(err))
+			continue
+		}
+
+		total.Imported += stats.Imported
+		total.Existing += stats.Existing
+		total.Invalid += stats.Invalid
+		total.Raw += stats.Raw
+		total.NotFound += stats.NotFound
+	}
+
+	be.logger.Info("cache imported",
+		uberzap.Int("modules", len(ids)),
+		uberzap.Int("imported", total.Imported),
+		uberzap.Int("existing", total.Existing),
+		uberzap.Int("invalid", total.Invalid),
+		uberzap.Int("raw", total.Raw),
+		uberzap.Int("not_found", total.NotFound),
+	)
+
+	return nil
+}
+
+func (be *backend) importModuleCache(ctx context.Context, id uint, from string, opts importCacheOptions) (importCacheStats, error) {
+	stats := importCacheStats{}
+
+	save, err := module.DecodeFile(be.workshopFilePath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		save, err = be.latestArchivedSave(ctx, id)
+	}
+
+	if err != nil {
+		return stats, fmt.Errorf("reading module: %w", err)
+	}
+
+	mod := module.NewTTSModule()
+	mod.ScanModule(save)
+	mod.ID = id
+
+	files, err := be.storage.Files().ListByModuleID(ctx, id)
+	if err != nil {
+		return stats, fmt.Errorf("list files: %w", err)
+	}
+
+	stored := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		stored[f.URL] = struct{}{}
+	}
+
+	adopted := make([]service.File, 0)
+
+	for _, mf := range mod.GetAll() {
+		if raw, ok := rawFolders[mf.Type]; ok {
+			key := raw.Folder + "/" + mf.GetFilename() + raw.Extension
+
+			for _, name := range mf.CacheFilenames() {
+				copied, err := downloader.Import(ctx, be.assets, filepath.Join(from, raw.Folder, name+raw.Extension), key, opts.Link)
+				if err != nil {
+					be.logger.Warn("importing raw file", uberzap.String("url", mf.URL), uberzap.Error(err))
+				} else if copied {
+					stats.Raw++
+				}
+			}
+		}
+
+		if _, ok := downloader.Lookup(ctx, be.assets, mf); ok {
+			stats.Existing++
+			continue
+		}
+
+		src, ok := cachedFilePath(from, &mf)
+		if !ok {
+			stats.NotFound++
+			continue
+		}
+
+		err = validateCachedFile(src, mf.Type)
+		if err != nil {
+			be.logger.Debug("invalid cached file", uberzap.String("path", src), uberzap.Error(err))
+			stats.Invalid++
+
+			continue
+		}
+
+		_, err = downloader.Import(ctx, be.assets, src, downloader.Key(mf), opts.Link)
+		if err != nil {
+			return stats, fmt.Errorf("importing %s: %w", src, err)
+		}
+
+		stats.Imported++
+
+		if _, ok := stored[mf.URL]; !ok {
+			var size int64
+			if info, err := os.Stat(src); err == nil {
+				size = info.Size()
+			}
+
+			adopted = append(adopted, service.File{
+				ModuleID:  id,
+				Type:      mf.Type,
+				URL:       mf.URL,
+				Extension: mf.Extension,
+				Size:      size,
+			})
+		}
+	}
+
+	err = be.storage.Files().BatchCreate(ctx, adopted...)
+	if err != nil {
+		return stats, fmt.Errorf("create files: %w", err)
+	}
+
+	return stats, nil
+}
+
+// cachedFilePath finds the file in the TTS cache, setting the image extension
+// when it is unknown.
+func cachedFilePath(from string, mf *module.ModuleFile) (string, bool) {
+	for _, name := range mf.CacheFilenames() {
+		base := filepath.Join(from, mf.GetFolder(), name)
+
+		if mf.GetExtension() != "" {
+			if _, err := os.Stat(base + mf.GetExtension()); err == nil {
+				return base + mf.GetExtension(), true
+			}
+
+			continue
+		}
+
+		matches, _ := filepath.Glob(base + ".*")
+		if len(matches) == 0 {
+			continue
+		}
+
+		mf.Extension = filepath.Ext(matches[0])
+
+		return matches[0], true
+	}
+
+	return "", false
+}
+
+// validateCachedFile rejects empty files and files whose content does not
+// match the type, e.g. HTML error pages TTS cached instead of the asset.
+func validateCachedFile(path string, t service.FileType) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return downloader.Validate(f, t)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+// workshopSource is the state of a workshop file a module was parsed from.
+type workshopSource struct {
+	Size    int64
+	ModTime time.Time
+	Hash    string
+}
+
+type scannedModule struct {
+	module.TTSModule
+
+	Source workshopSource
+	// Version archives the parsed workshop JSON.
+	Version *service.ModuleVersion
+	// Documents are indexed for full-text search.
+	Documents []service.SearchDocument
+	// Err is set when the workshop file could not be parsed.
+	Err error
+}
+
+// workshopScan decides which workshop files changed since the previous run.
+type workshopScan struct {
+	dir   string
+	full  bool
+	known map[uint]service.Module
+
+	be *backend
+
+	parsed  atomic.Int64
+	skipped atomic.Int64
+
+	mu sync.Mutex
+	// unchanged are IDs of skipped modules.
+	unchanged []uint
+}
+
+func (be *backend) newWorkshopScan(ctx context.Context, dir string, full bool) (*workshopScan, error) {
+	scan := &workshopScan{
+		dir:   dir,
+		full:  full,
+		known: make(map[uint]service.Module),
+		be:    be,
+	}
+
+	if full {
+		return scan, nil
+	}
+
+	mods, err := be.storage.Modules().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list modules: %w", err)
+	}
+
+	for _, m := range mods {
+		scan.known[m.ID] = m
+	}
+
+	return scan, nil
+}
+
+func (s *workshopScan) skip(id uint) {
+	s.skipped.Add(1)
+
+	s.mu.Lock()
+	s.unchanged = append(s.unchanged, id)
+	s.mu.Unlock()
+}
+
+// check returns the file state and content when the file has to be parsed.
+// Files with the stored size and mtime are skipped without reading, files with
+// a new mtime but the stored hash are skipped after refreshing the stored mtime.
+// Modules whose last sync failed to download files are never skipped.
+func (s *workshopScan) check(ctx context.Context, id uint, file os.DirEntry) (workshopSource, []byte, bool, error) {
+	info, err := file.Info()
+	if err != nil {
+		return workshopSource{}, nil, false, fmt.Errorf("file info: %w", err)
+	}
+
+	src := workshopSource{
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+
+	known, isKnown := s.known[id]
+	// files of purged modules are forgotten, they are registered again on return
+	isKnown = isKnown && known.Status != service.ModuleStatusPurged
+	skippable := !s.full && isKnown && known.FailedFiles == 0
+
+	if skippable && known.WorkshopSize == src.Size && known.WorkshopModTime.Equal(src.ModTime) {
+		s.skip(id)
+		return src, nil, false, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, file.Name()))
+	if err != nil {
+		return workshopSource{}, nil, false, fmt.Errorf("read file: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	src.Hash = hex.EncodeToString(sum[:])
+
+	if skippable && known.WorkshopHash == src.Hash {
+		s.be.writeMu.Lock()
+		err = s.be.storage.Modules().Update(ctx, &service.Module{
+			ID:              id,
+			WorkshopSize:    src.Size,
+			WorkshopModTime: src.ModTime,
+		})
+		s.be.writeMu.Unlock()
+
+		if err != nil {
+			s.be.logger.Warn("updating workshop file state", uberzap.Uint("id", id), uberzap.Error(err))
+		}
+
+		s.skip(id)
+
+		return src, nil, false, nil
+	}
+
+	s.parsed.Add(1)
+
+	return src, data, true, nil
+}
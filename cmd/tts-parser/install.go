@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+// Install copies stored files of the given modules, or of every stored module,
+// into TTS Mods directories. Without targets the configured TTS Mods directory
+// and install paths are used.
+func (be *backend) Install(ctx context.Context, ids []uint, targets []string) error {
+	ids, err := be.moduleIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		targets = append([]string{be.cfg.TTS.ModsPath}, be.cfg.Downloader.InstallPaths...)
+	}
+
+	installed, failed, err := be.installModules(ctx, ids, targets)
+	if err != nil {
+		return err
+	}
+
+	be.logger.Info("modules installed",
+		uberzap.Int("modules", len(ids)),
+		uberzap.Strings("targets", targets),
+		uberzap.Int("installed", installed),
+		uberzap.Int("failed", failed),
+	)
+
+	return nil
+}
+
+// installModules copies stored files of the modules into targets and counts
+// installed copies and files which failed to install.
+func (be *backend) installModules(ctx context.Context, ids []uint, targets []string) (int, int, error) {
+	c := downloader.NewClient(be.logger, be.assets, downloader.Options{InstallPaths: targets})
+
+	installed, failed := 0, 0
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		files, err := be.storage.Files().ListByModuleID(ctx, id)
+		if err != nil {
+			return installed, failed, fmt.Errorf("list files: %w", err)
+		}
+
+		for _, f := range files {
+			n, err := c.Install(ctx, module.ModuleFile{URL: f.URL, Type: f.Type, Extension: f.Extension})
+			if err != nil {
+				be.logger.Warn("install file", uberzap.Uint("id", id), uberzap.String("url", f.URL), uberzap.Error(err))
+				failed++
+
+				continue
+			}
+
+			installed += n
+		}
+	}
+
+	return installed, failed, nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+type listOptions struct {
+	Players    int
+	Tags       []string
+	MaxTime    int
+	GameType   string
+	Complexity string
+	// All includes removed and purged modules.
+	All    bool
+	Output outputFormat
+}
+
+type moduleListEntry struct {
+	ID             uint                 `json:"id" yaml:"id"`
+	Name           string               `json:"name" yaml:"name"`
+	Status         service.ModuleStatus `json:"status" yaml:"status"`
+	Files          int                  `json:"files" yaml:"files"`
+	Bytes          int64                `json:"bytes" yaml:"bytes"`
+	FailedFiles    int                  `json:"failed_files" yaml:"failed_files"`
+	SyncedAt       *time.Time           `json:"synced_at,omitempty" yaml:"synced_at,omitempty"`
+	PlayerCounts   []int                `json:"player_counts,omitempty" yaml:"player_counts,omitempty"`
+	MinPlayingTime int                  `json:"min_playing_time,omitempty" yaml:"min_playing_time,omitempty"`
+	MaxPlayingTime int                  `json:"max_playing_time,omitempty" yaml:"max_playing_time,omitempty"`
+	GameType       string               `json:"game_type,omitempty" yaml:"game_type,omitempty"`
+	GameComplexity string               `json:"game_complexity,omitempty" yaml:"game_complexity,omitempty"`
+	Tags           []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type moduleListReport []moduleListEntry
+
+func (r moduleListReport) writeTable(w io.Writer) {
+	fmt.Fprintln(w, "ID\tSTATUS\tFILES\tSIZE\tFAILED\tSYNCED\tPLAYERS\tTIME\tTYPE\tCOMPLEXITY\tNAME\tTAGS")
+
+	for _, m := range r {
+		synced := time.Time{}
+		if m.SyncedAt != nil {
+			synced = *m.SyncedAt
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			m.ID,
+			m.Status,
+			m.Files,
+			formatBytes(m.Bytes),
+			m.FailedFiles,
+			formatTime(synced),
+			formatPlayerCounts(m.PlayerCounts),
+			formatPlayingTime(m.MinPlayingTime, m.MaxPlayingTime),
+			m.GameType,
+			m.GameComplexity,
+			m.Name,
+			strings.Join(m.Tags, ", "),
+		)
+	}
+}
+
+func (r moduleListReport) csvRecords() [][]string {
+	records := [][]string{{
+		"id", "name", "status", "files", "bytes", "failed_files", "synced_at",
+		"players", "min_playing_time", "max_playing_time", "game_type", "game_complexity", "tags",
+	}}
+
+	for _, m := range r {
+		synced := ""
+		if m.SyncedAt != nil {
+			synced = m.SyncedAt.Format(time.RFC3339)
+		}
+
+		records = append(records, []string{
+			strconv.FormatUint(uint64(m.ID), 10),
+			m.Name,
+			string(m.Status),
+			strconv.Itoa(m.Files),
+			strconv.FormatInt(m.Bytes, 10),
+			strconv.Itoa(m.FailedFiles),
+			synced,
+			formatPlayerCounts(m.PlayerCounts),
+			strconv.Itoa(m.MinPlayingTime),
+			strconv.Itoa(m.MaxPlayingTime),
+			m.GameType,
+			m.GameComplexity,
+			strings.Join(m.Tags, ";"),
+		})
+	}
+
+	return records
+}
+
+// List prints stored modules matching every given filter with their file
+// counts and sizes.
+func (be *backend) List(ctx context.Context, opts listOptions) error {
+	filter := service.ModuleFilter{
+		Players:        opts.Players,
+		Tags:           opts.Tags,
+		MaxPlayingTime: opts.MaxTime,
+		GameType:       opts.GameType,
+		GameComplexity: opts.Complexity,
+	}
+
+	if !opts.All {
+		filter.Statuses = []service.ModuleStatus{service.ModuleStatusActive}
+	}
+
+	mods, err := be.storage.Modules().Find(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("find modules: %w", err)
+	}
+
+	stats, err := be.storage.Files().Stats(ctx)
+	if err != nil {
+		return fmt.Errorf("file stats: %w", err)
+	}
+
+	byModule := make(map[uint]service.ModuleFileStats, len(stats))
+	for _, s := range stats {
+		byModule[s.ModuleID] = s
+	}
+
+	r := make(moduleListReport, 0, len(mods))
+
+	for _, m := range mods {
+		r = append(r, moduleListEntry{
+			ID:             m.ID,
+			Name:           m.Name,
+			Status:         m.Status,
+			Files:          byModule[m.ID].Files,
+			Bytes:          byModule[m.ID].Bytes,
+			FailedFiles:    m.FailedFiles,
+			SyncedAt:       optionalTime(m.SyncedAt),
+			PlayerCounts:   m.PlayerCounts,
+			MinPlayingTime: m.MinPlayingTime,
+			MaxPlayingTime: m.MaxPlayingTime,
+			GameType:       m.GameType,
+			GameComplexity: m.GameComplexity,
+			Tags:           m.Tags,
+		})
+	}
+
+	return writeReport(opts.Output, r)
+}
+
+// formatPlayerCounts prints consecutive counts as a range, e.g. 2-4 or 1,3,5.
+func formatPlayerCounts(counts []int) string {
+	if len(counts) == 0 {
+		return ""
+	}
+
+	if counts[len(counts)-1]-counts[0] == len(counts)-1 {
+		if len(counts) == 1 {
+			return strconv.Itoa(counts[0])
+		}
+
+		return fmt.Sprintf("%d-%d", counts[0], counts[len(counts)-1])
+	}
+
+	parts := make([]string, 0, len(counts))
+	for _, n := range counts {
+		parts = append(parts, strconv.Itoa(n))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func formatPlayingTime(from, to int) string {
+	switch {
+	case to == 0:
+		return ""
+	case from == to || from == 0:
+		return fmt.Sprintf("%dm", to)
+	default:
+		return fmt.Sprintf("%d-%dm", from, to)
+	}
+}
@@ -4,36 +4,112 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/ldmonster/tts-parser/internal/downloader"
 	"github.com/ldmonster/tts-parser/internal/zap"
 
 	uberzap "go.uber.org/zap"
 )
 
-func main() {
-	Execute()
+// exitInterrupted is returned by commands stopped by a signal, following the
+// shell convention of 128 + SIGINT.
+const exitInterrupted = 130
+
+// exitError ends the process with code, Execute exits with it once deferred
+// cleanup of the command has run.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// globalOptions are persistent flags set explicitly, they take precedence over the config.
+type globalOptions struct {
+	TempDir string
+	// Timeout is nil when the flag is not set, zero disables the deadline.
+	Timeout   *time.Duration
+	Overwrite downloader.OverwritePolicy
 }
 
-func start() {
+var globalOpts globalOptions
+
+func (opts globalOptions) apply(cfg *Config) {
+	if opts.TempDir != "" {
+		cfg.Downloader.TempDir = opts.TempDir
+		cfg.flagKeys["downloader.temp_dir"] = true
+	}
+
+	if opts.Timeout != nil {
+		cfg.Downloader.Timeout = *opts.Timeout
+		cfg.flagKeys["downloader.timeout"] = true
+	}
+
+	if opts.Overwrite != "" {
+		cfg.Downloader.Overwrite = opts.Overwrite
+		cfg.flagKeys["downloader.overwrite"] = true
+	}
+}
+
+// loadConfig reads the config files, the environment and flags.
+func loadConfig() (*Config, error) {
 	cfg := NewConfig()
 
 	err := cfg.AutoLoadEnvs()
-	if err != nil && (!errors.Is(err, ErrEnvFileIsNotFound) && !errors.Is(err, ErrConfigFileIsNotFound)) {
-		panic(err)
+	if err != nil {
+		return nil, err
 	}
 
+	err = cfg.Parse()
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
 
-	err = cfg.Parse()
+	globalOpts.apply(cfg)
+
+	return cfg, nil
+}
+
+func main() {
+	Execute()
+}
+
+func start(opts startOptions) error {
+	return run(func(ctx context.Context, b *backend) error {
+		return b.Start(ctx, opts)
+	})
+}
+
+// run prepares config, logger and backend with an up to date schema and
+// executes fn with a context cancelled on SIGINT/SIGTERM. fn is expected to
+// wind down on cancellation, a second signal is handled by the runtime and
+// kills the process. Errors of fn are returned, for interrupted commands
+// wrapped into an exitError with exitInterrupted.
+func run(fn func(ctx context.Context, b *backend) error) error {
+	return runWith(initOptions{Migrate: true}, fn)
+}
+
+func runWith(opts initOptions, fn func(ctx context.Context, b *backend) error) error {
+	cfg, err := loadConfig()
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	logger, err := zap.NewProductionZaplogger("log.txt", cfg.LogLevel)
+	logger, err := zap.NewProductionZaplogger(cfg.Log.File, cfg.LogLevel)
 	if err != nil {
 		panic(err)
 	}
@@ -46,15 +122,41 @@ func start() {
 
 	b := NewBackend(cfg, logger)
 
-	err = b.init()
+	err = b.init(opts)
 	if err != nil {
-		logger.Fatal("backend initialization", uberzap.Error(err))
+		return fmt.Errorf("backend initialization: %w", err)
 	}
-	logger.Error("download module", uberzap.Error(err))
 
-	ctx, stopNotify := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
 
-	b.Start(ctx)
+		logger.Warn("shutting down, interrupt again to quit immediately")
+		cancel()
+
+		// the next signal gets the default handling
+		signal.Stop(signals)
+	}()
+
+	err = fn(ctx, b)
+
+	if ctx.Err() != nil {
+		if err == nil {
+			err = errors.New("interrupted")
+		}
+
+		return &exitError{code: exitInterrupted, err: err}
+	}
 
-	stopNotify()
+	return err
 }
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	uberzap "go.uber.org/zap"
+)
+
+func (be *backend) migrator() (service.Migrator, error) {
+	m, ok := be.storage.(service.Migrator)
+	if !ok {
+		return nil, fmt.Errorf("storage driver %q has no schema migrations", be.cfg.Storage.Driver)
+	}
+
+	return m, nil
+}
+
+// MigrateStatus prints applied and pending schema migrations.
+func (be *backend) MigrateStatus(ctx context.Context) error {
+	m, err := be.migrator()
+	if err != nil {
+		return err
+	}
+
+	migrations, err := m.MigrationStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("migration status: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATUS\tAPPLIED\tNAME")
+
+	for _, mig := range migrations {
+		status := "applied"
+
+		switch {
+		case mig.Unknown:
+			status = "unknown"
+		case mig.AppliedAt.IsZero():
+			status = "pending"
+		}
+
+		applied := ""
+		if !mig.AppliedAt.IsZero() {
+			applied = mig.AppliedAt.Local().Format(time.DateTime)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", mig.Version, status, applied, mig.Name)
+	}
+
+	return w.Flush()
+}
+
+// MigrateUp applies up to steps pending migrations, all of them when steps is not positive.
+func (be *backend) MigrateUp(ctx context.Context, steps int) error {
+	m, err := be.migrator()
+	if err != nil {
+		return err
+	}
+
+	applied, err := m.MigrateUp(ctx, steps)
+	if err != nil {
+		return err
+	}
+
+	be.logger.Info("migrations applied", uberzap.Int("applied", applied))
+
+	return nil
+}
+
+// MigrateDown reverts up to steps applied migrations, all of them when steps is
+// not positive. The command asks for reverting all with --all.
+func (be *backend) MigrateDown(ctx context.Context, steps int) error {
+	m, err := be.migrator()
+	if err != nil {
+		return err
+	}
+
+	reverted, err := m.MigrateDown(ctx, steps)
+	if err != nil {
+		return err
+	}
+
+	be.logger.Info("migrations reverted", uberzap.Int("reverted", reverted))
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+	outputCSV   outputFormat = "csv"
+	outputYAML  outputFormat = "yaml"
+)
+
+func parseOutputFormat(arg string) (outputFormat, error) {
+	switch f := outputFormat(arg); f {
+	case outputTable, outputJSON, outputCSV, outputYAML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, expected table, json, csv or yaml", arg)
+	}
+}
+
+// report is printed as a table or CSV, JSON and YAML marshal the report itself.
+type report interface {
+	writeTable(w io.Writer)
+	// csvRecords returns the header followed by the rows.
+	csvRecords() [][]string
+}
+
+func writeReport(format outputFormat, r report) error {
+	switch format {
+	case outputTable, "":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		r.writeTable(w)
+
+		return w.Flush()
+	case outputJSON:
+		return writeJSON(os.Stdout, r)
+	case outputYAML:
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+
+		err := enc.Encode(r)
+		if err != nil {
+			return err
+		}
+
+		return enc.Close()
+	case outputCSV:
+		return csv.NewWriter(os.Stdout).WriteAll(r.csvRecords())
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(v)
+}
+
+// optionalTime is omitted from JSON and YAML when zero.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+// syncModule downloads files of the scanned module with the download options
+// of the run and reconciles the stored module and its file set with it in a single transaction.
+func (be *backend) syncModule(ctx context.Context, dlOpts downloader.Options, scanned *scannedModule) (service.RunModule, error) {
+	mod := &scanned.TTSModule
+
+	result := service.RunModule{ModuleID: mod.ID, Name: mod.Name}
+
+	stored, err := be.storage.Files().ListByModuleID(ctx, mod.ID)
+	if err != nil {
+		return result, fmt.Errorf("list files: %w", err)
+	}
+
+	// known extensions let the downloader find images already in the store
+	mod.MergeFiles(stored)
+
+	c := downloader.NewClient(be.logger, be.assets, dlOpts)
+	downloaded := c.DownloadModule(ctx, mod, stored...)
+
+	// files cancelled midway are not failures, the module is synced by the next run
+	if ctx.Err() != nil {
+		return result, fmt.Errorf("download interrupted: %w", ctx.Err())
+	}
+
+	changes := mod.Reconcile(stored, downloaded.Available())
+
+	failures := make([]service.FileFailure, 0, len(downloaded.Failed))
+	for _, f := range downloaded.Failed {
+		failures = append(failures, service.FileFailure{
+			ModuleID: mod.ID,
+			Type:     f.File.Type,
+			URL:      f.File.URL,
+			Error:    f.Err.Error(),
+			FailedAt: time.Now(),
+		})
+	}
+
+	result.FilesDownloaded = len(downloaded.Downloaded)
+	result.FilesExisting = len(downloaded.Existing)
+	result.FilesFailed = len(downloaded.Failed)
+
+	for _, f := range downloaded.Downloaded {
+		result.Bytes += f.Size
+	}
+
+	be.writeMu.Lock()
+	err = be.applyFileChanges(ctx, scanned, changes, failures)
+	be.writeMu.Unlock()
+
+	if err != nil {
+		return result, fmt.Errorf("apply changes: %w", err)
+	}
+
+	result.FilesAdded = len(changes.Added)
+	result.FilesRemoved = len(changes.Removed)
+
+	be.logger.Info("module synced",
+		uberzap.String("module", mod.Name),
+		uberzap.Uint("id", mod.ID),
+		uberzap.Int("downloaded", len(downloaded.Downloaded)),
+		uberzap.Int("existing", len(downloaded.Existing)),
+		uberzap.Int("failed", len(downloaded.Failed)),
+		uberzap.Int("installed", downloaded.Installed),
+		uberzap.Int("added", len(changes.Added)),
+		uberzap.Int("removed", len(changes.Removed)),
+		uberzap.Int("unchanged", len(changes.Unchanged)),
+		uberzap.Int("missing", len(changes.Missing)),
+	)
+
+	for _, f := range changes.Added {
+		be.logger.Debug("file added", uberzap.Uint("id", mod.ID), uberzap.String("url", f.URL))
+	}
+
+	for _, f := range changes.Removed {
+		be.logger.Debug("file removed", uberzap.Uint("id", mod.ID), uberzap.String("url", f.URL))
+	}
+
+	return result, nil
+}
+
+func (be *backend) applyFileChanges(ctx context.Context, scanned *scannedModule, changes module.FileChanges, failures []service.FileFailure) error {
+	return be.storage.Transaction(ctx, func(ctx context.Context) error {
+		err := be.storage.Modules().Upsert(ctx, &service.Module{
+			ID:              scanned.ID,
+			Name:            scanned.Name,
+			EpochTime:       scanned.EpochTime,
+			VersionNumber:   scanned.VersionNumber.Original(),
+			SaveEpochTime:   scanned.SaveEpochTime,
+			WorkshopSize:    scanned.Source.Size,
+			WorkshopModTime: scanned.Source.ModTime,
+			WorkshopHash:    scanned.Source.Hash,
+			Status:          service.ModuleStatusActive,
+			LastSeenAt:      time.Now(),
+			SyncedAt:        time.Now(),
+			FailedFiles:     len(changes.Missing),
+			GameMode:        scanned.GameMode,
+			GameType:        scanned.GameType,
+			GameComplexity:  scanned.GameComplexity,
+			MinPlayingTime:  scanned.MinPlayingTime,
+			MaxPlayingTime:  scanned.MaxPlayingTime,
+			PlayerCounts:    scanned.PlayerCounts,
+			Tags:            scanned.Tags,
+			Table:           scanned.Table,
+			Sky:             scanned.Sky,
+			Note:            scanned.Note,
+		})
+		if err != nil {
+			return fmt.Errorf("upsert module: %w", err)
+		}
+
+		if scanned.Version != nil {
+			err = be.storage.ModuleVersions().Create(ctx, scanned.Version)
+			if err != nil {
+				return fmt.Errorf("archive version: %w", err)
+			}
+		}
+
+		err = be.storage.Search().ReplaceModule(ctx, scanned.ID, scanned.Documents...)
+		if err != nil {
+			return fmt.Errorf("index module: %w", err)
+		}
+
+		err = be.storage.FileFailures().ReplaceModule(ctx, scanned.ID, failures...)
+		if err != nil {
+			return fmt.Errorf("record failures: %w", err)
+		}
+
+		if changes.IsEmpty() {
+			return nil
+		}
+
+		removed := make([]uint, 0, len(changes.Removed))
+		for _, f := range changes.Removed {
+			removed = append(removed, f.ID)
+		}
+
+		err = be.storage.Files().DeleteByIDs(ctx, removed...)
+		if err != nil {
+			return fmt.Errorf("delete removed files: %w", err)
+		}
+
+		err = be.storage.Files().BatchCreate(ctx, changes.Added...)
+		if err != nil {
+			return fmt.Errorf("create added files: %w", err)
+		}
+
+		err = be.storage.Files().UpdateContents(ctx, changes.Updated...)
+		if err != nil {
+			return fmt.Errorf("update file contents: %w", err)
+		}
+
+		return nil
+	})
+}
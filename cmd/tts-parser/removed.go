@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	uberzap "go.uber.org/zap"
+)
+
+// updateModuleStatuses marks modules whose workshop file was seen as active and
+// active modules without a workshop file as removed.
+func (be *backend) updateModuleStatuses(ctx context.Context, seen []uint) error {
+	now := time.Now()
+
+	// an empty workshop directory is rather a wrong path than every module taken down
+	if len(seen) == 0 {
+		be.logger.Warn("no workshop files found, module statuses are not updated")
+		return nil
+	}
+
+	mods, err := be.storage.Modules().List(ctx)
+	if err != nil {
+		return fmt.Errorf("list modules: %w", err)
+	}
+
+	seenIDs := make(map[uint]struct{}, len(seen))
+	for _, id := range seen {
+		seenIDs[id] = struct{}{}
+	}
+
+	removed := make([]uint, 0)
+
+	for _, m := range mods {
+		if _, ok := seenIDs[m.ID]; ok || m.Status != service.ModuleStatusActive {
+			continue
+		}
+
+		removed = append(removed, m.ID)
+
+		be.logger.Warn("module is removed from workshop", uberzap.Uint("id", m.ID), uberzap.String("module", m.Name), uberzap.Time("last_seen", m.LastSeenAt))
+	}
+
+	return be.storage.Transaction(ctx, func(ctx context.Context) error {
+		err := be.storage.Modules().MarkSeen(ctx, now, seen...)
+		if err != nil {
+			return fmt.Errorf("mark seen: %w", err)
+		}
+
+		err = be.storage.Modules().MarkRemoved(ctx, now, removed...)
+		if err != nil {
+			return fmt.Errorf("mark removed: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ListRemoved prints modules which are no longer in the Workshop directory.
+func (be *backend) ListRemoved(ctx context.Context) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tLAST SEEN\tREMOVED\tVERSIONS\tNAME")
+
+	for _, status := range []service.ModuleStatus{service.ModuleStatusRemoved, service.ModuleStatusPurged} {
+		mods, err := be.storage.Modules().ListByStatus(ctx, status)
+		if err != nil {
+			return fmt.Errorf("list modules: %w", err)
+		}
+
+		for _, m := range mods {
+			versions, err := be.storage.ModuleVersions().ListByModuleID(ctx, m.ID)
+			if err != nil {
+				return fmt.Errorf("list versions: %w", err)
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\n",
+				m.ID,
+				m.Status,
+				formatTime(m.LastSeenAt),
+				formatTime(m.RemovedAt),
+				len(versions),
+				m.Name,
+			)
+		}
+	}
+
+	return w.Flush()
+}
+
+// Restore writes the archived version of a removed module back which matches
+// its last workshop file, the latest one when that file was never hashed.
+func (be *backend) Restore(ctx context.Context, id uint, opts rollbackOptions) error {
+	m, err := be.storage.Modules().Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get module %d: %w", id, err)
+	}
+
+	version := m.WorkshopHash
+	if version == "" {
+		be.logger.Warn("workshop file of module is unknown, restoring the latest version", uberzap.Uint("id", id))
+
+		version = "latest"
+	}
+
+	return be.Rollback(ctx, id, version, opts)
+}
+
+// Purge releases files of a removed module for garbage collection. The module
+// and its archived versions are kept.
+func (be *backend) Purge(ctx context.Context, id uint) error {
+	m, err := be.storage.Modules().Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get module %d: %w", id, err)
+	}
+
+	if m.Status == service.ModuleStatusActive {
+		return fmt.Errorf("module %d is still in the workshop directory", id)
+	}
+
+	err = be.storage.Transaction(ctx, func(ctx context.Context) error {
+		err := be.storage.Files().DeleteByModuleID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("delete files: %w", err)
+		}
+
+		err = be.storage.Search().ReplaceModule(ctx, id)
+		if err != nil {
+			return fmt.Errorf("delete search documents: %w", err)
+		}
+
+		err = be.storage.FileFailures().ReplaceModule(ctx, id)
+		if err != nil {
+			return fmt.Errorf("delete failures: %w", err)
+		}
+
+		return be.storage.Modules().UpdateStatus(ctx, id, service.ModuleStatusPurged)
+	})
+	if err != nil {
+		return err
+	}
+
+	be.logger.Info("module purged", uberzap.Uint("id", id), uberzap.String("module", m.Name))
+
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Local().Format(time.DateTime)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/assetstore"
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+type rewriteMode string
+
+const (
+	rewriteModeFile   rewriteMode = "file"
+	rewriteModeMirror rewriteMode = "mirror"
+)
+
+type rewriteOptions struct {
+	Mode      rewriteMode
+	MirrorURL string
+	Name      string
+	Force     bool
+}
+
+// Rewrite maps every asset URL of the workshop module to a local or mirrored copy
+// and writes the result as a new save into the TTS Saves folder.
+func (be *backend) Rewrite(ctx context.Context, id uint, opts rewriteOptions) error {
+	switch opts.Mode {
+	case rewriteModeFile:
+	case rewriteModeMirror:
+		if opts.MirrorURL == "" {
+			return errors.New("mirror mode requires a mirror url")
+		}
+	default:
+		return fmt.Errorf("unknown rewrite mode %q", opts.Mode)
+	}
+
+	pather, isLocal := be.assets.(assetstore.Pather)
+	if opts.Mode == rewriteModeFile && !isLocal {
+		return errors.New("file mode requires the local assets backend")
+	}
+
+	mod, err := module.DecodeFile(be.workshopFilePath(id))
+	if err != nil {
+		return fmt.Errorf("reading workshop module: %w", err)
+	}
+
+	scanned := module.NewTTSModule()
+	scanned.ScanModule(mod)
+
+	files, err := be.storage.Files().ListByModuleID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+
+	scanned.MergeFiles(files)
+
+	all := scanned.GetAll()
+	hashes := make(map[string]string, len(all))
+	missing := make(map[string]struct{})
+
+	rewritten := mod.RewriteURLs(func(u string, _ service.FileType) (string, bool) {
+		mf, ok := all[module.FixURL(u)]
+		if !ok {
+			return "", false
+		}
+
+		info, ok := downloader.Lookup(ctx, be.assets, mf)
+		if !ok {
+			missing[mf.URL] = struct{}{}
+			return "", false
+		}
+
+		switch opts.Mode {
+		case rewriteModeMirror:
+			sum, ok := hashes[info.Key]
+			if !ok {
+				var err error
+
+				sum, err = be.assetSHA256(ctx, info.Key)
+				if err != nil {
+					be.logger.Warn("hashing asset", uberzap.String("key", info.Key), uberzap.Error(err))
+					return "", false
+				}
+
+				hashes[info.Key] = sum
+			}
+
+			return strings.TrimSuffix(opts.MirrorURL, "/") + "/" + sum, true
+		default:
+			local, err := filepath.Abs(pather.Path(info.Key))
+			if err != nil {
+				return "", false
+			}
+
+			// absolute Windows paths lack the leading slash of a file URL path
+			local = filepath.ToSlash(local)
+			if !strings.HasPrefix(local, "/") {
+				local = "/" + local
+			}
+
+			return "file://" + local, true
+		}
+	})
+
+	name := opts.Name
+	if name == "" {
+		name = fmt.Sprintf("%d", id)
+	}
+
+	target := filepath.Join(be.cfg.TTS.SavesPath, name+".json")
+
+	err = writeSave(target, mod, opts.Force)
+	if err != nil {
+		return err
+	}
+
+	be.logger.Info("module rewritten",
+		uberzap.Uint("id", id),
+		uberzap.String("save", target),
+		uberzap.Int("rewritten", rewritten),
+		uberzap.Int("missing", len(missing)),
+	)
+
+	for u := range missing {
+		be.logger.Debug("asset is not cached", uberzap.String("url", u))
+	}
+
+	return nil
+}
+
+func (be *backend) assetSHA256(ctx context.Context, key string) (string, error) {
+	r, err := be.assets.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, r)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+var ErrSaveExists = errors.New("save already exists")
+
+func writeSave(path string, mod *module.Module, force bool) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s: %w", path, ErrSaveExists)
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0o777)
+	if err != nil {
+		return fmt.Errorf("creating directories: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating save: %w", err)
+	}
+	defer f.Close()
+
+	err = module.Encode(f, mod)
+	if err != nil {
+		return fmt.Errorf("writing save: %w", err)
+	}
+
+	return nil
+}
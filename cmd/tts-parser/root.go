@@ -4,8 +4,14 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ldmonster/tts-parser/internal/downloader"
 
 	"github.com/spf13/cobra"
 )
@@ -16,49 +22,806 @@ func Execute() {
 		Short: "Tool for parsing and managing Tabletop Simulator modules",
 		Long: `A CLI tool for parsing Tabletop Simulator module files (.json), downloading assets,
 creating backups, and auditing downloaded files.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// arguments are valid by now, errors of the command itself need no usage
+			cmd.SilenceUsage = true
+
+			if f := cmd.Flags().Lookup("temp-dir"); f != nil && f.Changed {
+				globalOpts.TempDir = f.Value.String()
+			}
+
+			if f := cmd.Flags().Lookup("timeout"); f != nil && f.Changed {
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+				globalOpts.Timeout = &timeout
+			}
+
+			if f := cmd.Flags().Lookup("overwrite"); f != nil && f.Changed {
+				policy, err := downloader.ParseOverwritePolicy(f.Value.String())
+				if err != nil {
+					return err
+				}
+
+				globalOpts.Overwrite = policy
+			}
+
+			return nil
+		},
 	}
 
 	var downloadCmd = &cobra.Command{
-		Use:   "download [module_path]",
+		Use:   "download [module_id...]",
 		Short: "Download module assets",
-		Long:  `Download all assets referenced in a Tabletop Simulator module file`,
-		Run: func(cmd *cobra.Command, args []string) {
-			start()
+		Long: `Download all assets referenced by the given workshop modules, or by every module in the workshop
+directory. Every download is recorded as a run, see runs list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseModuleIDs(args)
+			if err != nil {
+				return err
+			}
+
+			full, _ := cmd.Flags().GetBool("full")
+			install, _ := cmd.Flags().GetStringArray("install")
+			installTTS, _ := cmd.Flags().GetBool("install-tts")
+			report, _ := cmd.Flags().GetString("report")
+
+			var bandwidth *int64
+
+			if f := cmd.Flags().Lookup("bandwidth"); f.Changed {
+				n, err := parseByteSize(f.Value.String())
+				if err != nil {
+					return fmt.Errorf("invalid bandwidth: %w", err)
+				}
+
+				bandwidth = &n
+			}
+
+			return start(startOptions{
+				Full:       full,
+				Install:    install,
+				InstallTTS: installTTS,
+				Modules:    ids,
+				Command:    cmd.Root().Name() + " " + strings.Join(os.Args[1:], " "),
+				Report:     report,
+				Bandwidth:  bandwidth,
+			})
 		},
 	}
 
 	var backupCmd = &cobra.Command{
-		Use:   "backup [module_path]",
+		Use:   "backup [module_id...]",
 		Short: "Backup module files",
-		Long:  `Create a backup of module files and downloaded assets`,
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("Backing up module files...")
+		Long: `Copy the workshop JSON and downloaded assets of the given modules, or of every module in the DB,
+into a directory laid out like the TTS Mods directory`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseModuleIDs(args)
+			if err != nil {
+				return err
+			}
+
+			output, _ := cmd.Flags().GetString("output")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Backup(ctx, ids, output)
+			})
 		},
 	}
 
 	var auditCmd = &cobra.Command{
-		Use:   "audit [module_path]",
+		Use:   "audit [module_id...]",
 		Short: "Audit module files",
-		Long:  `Check integrity of downloaded module assets`,
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("Auditing module files...")
+		Long:  `Check that downloaded assets of the given modules, or of every module in the DB, are stored and intact`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseModuleIDs(args)
+			if err != nil {
+				return err
+			}
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Audit(ctx, ids)
+			})
+		},
+	}
+
+	var rewriteCmd = &cobra.Command{
+		Use:   "rewrite <module_id>",
+		Short: "Rewrite module asset URLs",
+		Long: `Rewrite every asset URL of a workshop module to a cached local file (file mode)
+or to a self-hosted mirror serving assets by SHA-256 (mirror mode), and write
+the result as a new save into the TTS Saves folder`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseModuleID(args[0])
+			if err != nil {
+				return err
+			}
+
+			mode, _ := cmd.Flags().GetString("mode")
+			mirrorURL, _ := cmd.Flags().GetString("mirror-url")
+			name, _ := cmd.Flags().GetString("name")
+			force, _ := cmd.Flags().GetBool("force")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Rewrite(ctx, id, rewriteOptions{
+					Mode:      rewriteMode(mode),
+					MirrorURL: mirrorURL,
+					Name:      name,
+					Force:     force,
+				})
+			})
+		},
+	}
+
+	var unpackCmd = &cobra.Command{
+		Use:   "unpack <module_id>",
+		Short: "Unpack a module into a directory tree",
+		Long: `Explode a workshop module into a directory: one folder per object with object.json,
+script.lua and ui.xml, and the global script and UI at the root`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseModuleID(args[0])
+			if err != nil {
+				return err
+			}
+
+			output, _ := cmd.Flags().GetString("output")
+			force, _ := cmd.Flags().GetBool("force")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Unpack(ctx, id, output, force)
+			})
+		},
+	}
+
+	var packCmd = &cobra.Command{
+		Use:   "pack <dir>",
+		Short: "Pack a directory tree into a save",
+		Long:  `Rebuild the save JSON from a directory tree produced by unpack`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			force, _ := cmd.Flags().GetBool("force")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Pack(ctx, args[0], output, force)
+			})
+		},
+	}
+
+	var scanScriptsCmd = &cobra.Command{
+		Use:   "scan-scripts [module_id...]",
+		Short: "Scan module scripts for malicious code",
+		Long: `Check global and object Lua scripts of the given modules, or of every module in the DB,
+for the self-replicating "tcejbo gninwapS" payload and other suspicious code`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseModuleIDs(args)
+			if err != nil {
+				return err
+			}
+
+			clean, _ := cmd.Flags().GetBool("clean")
+			output, _ := cmd.Flags().GetString("output")
+			force, _ := cmd.Flags().GetBool("force")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.ScanScripts(ctx, ids, scanScriptsOptions{
+					Clean:  clean,
+					Output: output,
+					Force:  force,
+				})
+			})
+		},
+	}
+
+	var catalogScriptsCmd = &cobra.Command{
+		Use:   "catalog-scripts [module_id...]",
+		Short: "Index module scripts",
+		Long: `Hash every Lua script of the given modules, or of every module in the DB, group identical
+scripts, unpack luabundle bundles into their modules and store them with the objects using them`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseModuleIDs(args)
+			if err != nil {
+				return err
+			}
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.CatalogScripts(ctx, ids)
+			})
+		},
+	}
+
+	var searchScriptsCmd = &cobra.Command{
+		Use:   "search-scripts <text>",
+		Short: "Search cataloged scripts",
+		Long:  `List objects whose scripts, or modules bundled into them, contain the text`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(func(ctx context.Context, b *backend) error {
+				return b.SearchScripts(ctx, args[0])
+			})
+		},
+	}
+
+	var historyCmd = &cobra.Command{
+		Use:   "history <module_id>",
+		Short: "List archived versions of a module",
+		Long:  `List every distinct workshop JSON archived for the module, oldest first`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseModuleID(args[0])
+			if err != nil {
+				return err
+			}
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.History(ctx, id)
+			})
+		},
+	}
+
+	var rollbackCmd = &cobra.Command{
+		Use:   "rollback <module_id> <version>",
+		Short: "Restore an archived version of a module",
+		Long: `Write an archived version, given by its number in history or a hash prefix or "latest",
+back into the Workshop folder or as a new save into the Saves folder`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseModuleID(args[0])
+			if err != nil {
+				return err
+			}
+
+			version := args[1]
+
+			to, _ := cmd.Flags().GetString("to")
+			force, _ := cmd.Flags().GetBool("force")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Rollback(ctx, id, version, rollbackOptions{
+					Target: rollbackTarget(to),
+					Force:  force,
+				})
+			})
+		},
+	}
+
+	var diffCmd = &cobra.Command{
+		Use:   "diff <module_id> [<old> <new>]",
+		Short: "Show what changed between two versions of a module",
+		Long: `Compare two archived versions, given by their number in history, a hash prefix, "latest" or "current"
+for the workshop file, and report added, removed, moved and renamed objects, script and asset changes`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 && len(args) != 3 {
+				return fmt.Errorf("accepts 1 or 3 arg(s), received %d", len(args))
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseModuleID(args[0])
+			if err != nil {
+				return err
+			}
+
+			var oldVersion, newVersion string
+			if len(args) == 3 {
+				oldVersion, newVersion = args[1], args[2]
+			}
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Diff(ctx, id, oldVersion, newVersion)
+			})
+		},
+	}
+
+	var removedCmd = &cobra.Command{
+		Use:   "removed",
+		Short: "List modules removed from the workshop",
+		Long:  `List modules which were unsubscribed or taken down, with the time they were seen last`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(func(ctx context.Context, b *backend) error {
+				return b.ListRemoved(ctx)
+			})
+		},
+	}
+
+	var restoreCmd = &cobra.Command{
+		Use:   "restore <module_id>",
+		Short: "Re-materialize a removed module from its history",
+		Long:  `Write the archived version matching the last workshop file of the module into the Workshop or Saves folder`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseModuleID(args[0])
+			if err != nil {
+				return err
+			}
+
+			to, _ := cmd.Flags().GetString("to")
+			force, _ := cmd.Flags().GetBool("force")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Restore(ctx, id, rollbackOptions{
+					Target: rollbackTarget(to),
+					Force:  force,
+				})
+			})
+		},
+	}
+
+	var purgeCmd = &cobra.Command{
+		Use:   "purge <module_id>",
+		Short: "Release files of a removed module for garbage collection",
+		Long:  `Forget files of a removed module so that gc may delete them, archived versions are kept`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseModuleID(args[0])
+			if err != nil {
+				return err
+			}
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Purge(ctx, id)
+			})
+		},
+	}
+
+	var gcCmd = &cobra.Command{
+		Use:   "gc",
+		Short: "Remove cached files no module references",
+		Long: `List cached files not referenced by any module and stored files missing on disk,
+delete them with --apply. Files of removed modules are kept until the module is purged`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			apply, _ := cmd.Flags().GetBool("apply")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.GC(ctx, gcOptions{Apply: apply})
+			})
+		},
+	}
+
+	var importCacheCmd = &cobra.Command{
+		Use:   "import-cache [module_id...]",
+		Short: "Adopt files from an existing TTS Mods cache",
+		Long: `Copy or hardlink files of the given modules, or of every module in the DB, found in the
+TTS Mods directory into the store, including raw .rawt/.rawm variants, and register them`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseModuleIDs(args)
+			if err != nil {
+				return err
+			}
+
+			from, _ := cmd.Flags().GetString("from")
+			link, _ := cmd.Flags().GetBool("link")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.ImportCache(ctx, ids, importCacheOptions{
+					From: from,
+					Link: link,
+				})
+			})
+		},
+	}
+
+	var installCmd = &cobra.Command{
+		Use:   "install [module_id...]",
+		Short: "Install stored files into a TTS Mods directory",
+		Long: `Copy stored files of the given modules, or of every module in the DB, where TTS expects them
+so that the modules load offline`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseModuleIDs(args)
+			if err != nil {
+				return err
+			}
+
+			to, _ := cmd.Flags().GetStringArray("to")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Install(ctx, ids, to)
+			})
+		},
+	}
+
+	var listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List stored modules",
+		Long: `List active modules, or every module with --all, matching all given filters. Metadata is read
+from the saves on download`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("output")
+
+			output, err := parseOutputFormat(format)
+			if err != nil {
+				return err
+			}
+
+			players, _ := cmd.Flags().GetInt("players")
+			tags, _ := cmd.Flags().GetStringArray("tag")
+			maxTime, _ := cmd.Flags().GetInt("max-time")
+			gameType, _ := cmd.Flags().GetString("type")
+			complexity, _ := cmd.Flags().GetString("complexity")
+			all, _ := cmd.Flags().GetBool("all")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.List(ctx, listOptions{
+					Players:    players,
+					Tags:       tags,
+					MaxTime:    maxTime,
+					GameType:   gameType,
+					Complexity: complexity,
+					All:        all,
+					Output:     output,
+				})
+			})
+		},
+	}
+
+	var showCmd = &cobra.Command{
+		Use:   "show <module_id>",
+		Short: "Show a stored module with its assets",
+		Long: `Show metadata of a module and its assets grouped by type, with their status in the asset store and
+the TTS Mods directory and the objects using them`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseModuleID(args[0])
+			if err != nil {
+				return err
+			}
+
+			format, _ := cmd.Flags().GetString("output")
+
+			output, err := parseOutputFormat(format)
+			if err != nil {
+				return err
+			}
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Show(ctx, id, output)
+			})
+		},
+	}
+
+	var statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Show library statistics",
+		Long: `Show the number of modules, files and bytes per type, the largest modules, hosts with the most files
+and the highest failure rates, files shared between modules, space taken by files with identical content and bytes
+downloaded per month`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("output")
+
+			output, err := parseOutputFormat(format)
+			if err != nil {
+				return err
+			}
+
+			top, _ := cmd.Flags().GetInt("top")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Stats(ctx, statsOptions{
+					Top:    top,
+					Output: output,
+				})
+			})
+		},
+	}
+
+	var searchCmd = &cobra.Command{
+		Use:   "search <query...>",
+		Short: "Search stored modules",
+		Long: `List modules whose names, game type and mode, tags, notes, object nicknames and descriptions or
+notebook tabs contain every word of the query, best matches first with the matching objects. Modules are
+indexed on download`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+			all, _ := cmd.Flags().GetBool("all")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.Search(ctx, strings.Join(args, " "), searchOptions{
+					Limit: limit,
+					All:   all,
+				})
+			})
+		},
+	}
+
+	var runsCmd = &cobra.Command{
+		Use:   "runs",
+		Short: "Inspect download runs",
+		Long:  `List download runs or show the result of every module processed by a run`,
+	}
+
+	var runsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List download runs, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("output")
+
+			output, err := parseOutputFormat(format)
+			if err != nil {
+				return err
+			}
+
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.RunsList(ctx, limit, output)
+			})
+		},
+	}
+
+	var runsShowCmd = &cobra.Command{
+		Use:   "show <run_id>",
+		Short: "Show a download run with its module results",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid run id %q: %w", args[0], err)
+			}
+
+			format, _ := cmd.Flags().GetString("output")
+
+			output, err := parseOutputFormat(format)
+			if err != nil {
+				return err
+			}
+
+			return run(func(ctx context.Context, b *backend) error {
+				return b.RunShow(ctx, uint(id), output)
+			})
+		},
+	}
+
+	var configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and create the configuration",
+		Long: `Settings are read from defaults, .config.yaml, the .env file and the environment, each taking
+precedence over the previous one, and finally from flags`,
+	}
+
+	var configShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration and where each setting comes from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("output")
+
+			output, err := parseOutputFormat(format)
+			if err != nil {
+				return err
+			}
+
+			return configShow(output)
+		},
+	}
+
+	var configInitCmd = &cobra.Command{
+		Use:   "init",
+		Short: "Write a config file documenting every setting with its default",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			force, _ := cmd.Flags().GetBool("force")
+
+			return configInit(output, force)
+		},
+	}
+
+	var migrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema",
+		Long: `Show, apply or revert versioned schema migrations. Pending migrations are applied by every other
+command as well, the SQLite database is backed up next to it before migrating`,
+	}
+
+	var migrateStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "List applied and pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWith(initOptions{}, func(ctx context.Context, b *backend) error {
+				return b.MigrateStatus(ctx)
+			})
+		},
+	}
+
+	var migrateUpCmd = &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps, _ := cmd.Flags().GetInt("steps")
+
+			return runWith(initOptions{}, func(ctx context.Context, b *backend) error {
+				return b.MigrateUp(ctx, steps)
+			})
+		},
+	}
+
+	var migrateDownCmd = &cobra.Command{
+		Use:   "down",
+		Short: "Revert applied migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			steps, _ := cmd.Flags().GetInt("steps")
+			all, _ := cmd.Flags().GetBool("all")
+
+			// reverting every migration drops every table, it has to be asked for explicitly
+			if !all && steps < 1 {
+				return fmt.Errorf("--steps must be at least 1, use --all to revert every migration")
+			}
+
+			if all {
+				steps = 0
+			}
+
+			return runWith(initOptions{}, func(ctx context.Context, b *backend) error {
+				return b.MigrateDown(ctx, steps)
+			})
 		},
 	}
 
 	// Global flags
-	rootCmd.PersistentFlags().StringP("temp-dir", "t", "tmp/", "Temporary download directory")
-	rootCmd.PersistentFlags().DurationP("timeout", "o", 0, "Download timeout duration (e.g. 30s, 1m)")
-	rootCmd.PersistentFlags().BoolP("overwrite", "w", false, "Overwrite existing files when downloading")
+	rootCmd.PersistentFlags().StringP("temp-dir", "t", "tmp/", "Directory downloaded files are stored in")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Deadline of a download run (e.g. 30m, 2h), 0 disables it")
+	rootCmd.PersistentFlags().StringP("overwrite", "w", string(downloader.OverwriteNever), "Download stored files again: never, always, if-changed or if-invalid")
+
+	// Download command flags
+	downloadCmd.Flags().Bool("full", false, "Parse and download every module, including unchanged ones")
+	downloadCmd.Flags().StringArray("install", nil, "Also install files into this TTS Mods directory, may be repeated")
+	downloadCmd.Flags().Bool("install-tts", false, "Also install files into the configured TTS Mods directory")
+	downloadCmd.Flags().String("report", "", "Write the run with its module results as JSON to this file")
+	downloadCmd.Flags().String("bandwidth", "", "Cap download speed per second (e.g. 2MB), 0 removes the configured cap")
+
+	// Install command flags
+	installCmd.Flags().StringArray("to", nil, "TTS Mods directory to install into, may be repeated (default: configured TTS Mods directory)")
 
 	// Backup command flags
 	backupCmd.Flags().StringP("output", "o", "backups/", "Backup output directory")
 
+	// Rewrite command flags
+	rewriteCmd.Flags().String("mode", string(rewriteModeFile), "Rewrite target: file or mirror")
+	rewriteCmd.Flags().String("mirror-url", "", "Base URL of the mirror, assets are addressed as <mirror-url>/<sha256>")
+	rewriteCmd.Flags().String("name", "", "Save file name without extension (default: module id)")
+	rewriteCmd.Flags().Bool("force", false, "Overwrite an existing save with the same name")
+
+	// Unpack command flags
+	unpackCmd.Flags().String("output", "", "Output directory (default: unpacked/<module_id>)")
+	unpackCmd.Flags().Bool("force", false, "Replace a previously unpacked tree, keeping unrelated files")
+
+	// Pack command flags
+	packCmd.Flags().String("output", "", "Output save file (default: <dir>.json)")
+	packCmd.Flags().Bool("force", false, "Overwrite an existing save file")
+
+	// Scan scripts command flags
+	scanScriptsCmd.Flags().Bool("clean", false, "Write cleaned copies of infected saves as <module_id>_clean.json")
+	scanScriptsCmd.Flags().String("output", "", "Directory for cleaned saves (default: TTS Saves folder)")
+	scanScriptsCmd.Flags().Bool("force", false, "Overwrite existing cleaned saves")
+
+	// Rollback command flags
+	rollbackCmd.Flags().String("to", string(rollbackTargetWorkshop), "Restore into: workshop or saves")
+	rollbackCmd.Flags().Bool("force", false, "Overwrite an existing save")
+
+	// Restore command flags
+	restoreCmd.Flags().String("to", string(rollbackTargetWorkshop), "Restore into: workshop or saves")
+	restoreCmd.Flags().Bool("force", false, "Overwrite an existing save")
+
+	// GC command flags
+	gcCmd.Flags().Bool("apply", false, "Delete unreferenced files and stale rows")
+
+	// Import cache command flags
+	importCacheCmd.Flags().String("from", "", "TTS Mods directory (default: configured mods path)")
+	importCacheCmd.Flags().Bool("link", false, "Hardlink files instead of copying them")
+
+	// List command flags
+	listCmd.Flags().Int("players", 0, "Only modules supporting this number of players")
+	listCmd.Flags().StringArray("tag", nil, "Only modules with this tag, may be repeated")
+	listCmd.Flags().Int("max-time", 0, "Only modules playable within this many minutes")
+	listCmd.Flags().String("type", "", "Only modules of this game type, e.g. \"Card Games\"")
+	listCmd.Flags().String("complexity", "", "Only modules of this complexity, e.g. Medium")
+	listCmd.Flags().Bool("all", false, "Include removed and purged modules")
+	listCmd.Flags().String("output", string(outputTable), "Output format: table, json, csv or yaml")
+
+	// Show command flags
+	showCmd.Flags().String("output", string(outputTable), "Output format: table, json, csv or yaml")
+
+	// Stats command flags
+	statsCmd.Flags().Int("top", 10, "Number of modules and hosts to print")
+	statsCmd.Flags().String("output", string(outputTable), "Output format: table, json, csv or yaml")
+
+	// Search command flags
+	searchCmd.Flags().Int("limit", 20, "Number of modules to print, 0 prints all")
+	searchCmd.Flags().Bool("all", false, "Include removed and purged modules")
+
+	// Runs command flags
+	runsListCmd.Flags().Int("limit", 20, "Number of runs to print, 0 prints all")
+	runsListCmd.Flags().String("output", string(outputTable), "Output format: table, json, csv or yaml")
+	runsShowCmd.Flags().String("output", string(outputTable), "Output format: table, json, csv or yaml")
+
+	// Config command flags
+	configShowCmd.Flags().String("output", string(outputTable), "Output format: table, json, csv or yaml")
+	configInitCmd.Flags().String("output", ".config.yaml", "Config file to write")
+	configInitCmd.Flags().Bool("force", false, "Overwrite an existing config file")
+
+	// Migrate command flags
+	migrateUpCmd.Flags().Int("steps", 0, "Number of migrations to apply, 0 applies all")
+	migrateDownCmd.Flags().Int("steps", 1, "Number of migrations to revert")
+	migrateDownCmd.Flags().Bool("all", false, "Revert every migration, dropping all data")
+	migrateDownCmd.MarkFlagsMutuallyExclusive("steps", "all")
+
+	runsCmd.AddCommand(runsListCmd)
+	runsCmd.AddCommand(runsShowCmd)
+
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configInitCmd)
+
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+
 	rootCmd.AddCommand(downloadCmd)
 	rootCmd.AddCommand(backupCmd)
 	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(rewriteCmd)
+	rootCmd.AddCommand(unpackCmd)
+	rootCmd.AddCommand(packCmd)
+	rootCmd.AddCommand(scanScriptsCmd)
+	rootCmd.AddCommand(catalogScriptsCmd)
+	rootCmd.AddCommand(searchScriptsCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(rollbackCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(removedCmd)
+	rootCmd.AddCommand(restoreCmd)
+	rootCmd.AddCommand(purgeCmd)
+	rootCmd.AddCommand(gcCmd)
+	rootCmd.AddCommand(importCacheCmd)
+	rootCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(runsCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(migrateCmd)
 
 	err := rootCmd.Execute()
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
+	}
+
 	if err != nil {
 		os.Exit(1)
 	}
 }
+
+func parseModuleID(arg string) (uint, error) {
+	id, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid module id %q: %w", arg, err)
+	}
+
+	return uint(id), nil
+}
+
+func parseModuleIDs(args []string) ([]uint, error) {
+	ids := make([]uint, 0, len(args))
+
+	for _, arg := range args {
+		id, err := parseModuleID(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	uberzap "go.uber.org/zap"
+)
+
+type runEntry struct {
+	ID      uint              `json:"id" yaml:"id"`
+	Command string            `json:"command" yaml:"command"`
+	Status  service.RunStatus `json:"status" yaml:"status"`
+	// Selected are module IDs the run was limited to.
+	Selected   []uint     `json:"selected,omitempty" yaml:"selected,omitempty"`
+	StartedAt  time.Time  `json:"started_at" yaml:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" yaml:"finished_at,omitempty"`
+	// Seconds is the duration of the run, zero while it is running.
+	Seconds         float64 `json:"seconds" yaml:"seconds"`
+	Parsed          int     `json:"parsed" yaml:"parsed"`
+	Skipped         int     `json:"skipped" yaml:"skipped"`
+	Failed          int     `json:"failed" yaml:"failed"`
+	FilesDownloaded int     `json:"files_downloaded" yaml:"files_downloaded"`
+	FilesExisting   int     `json:"files_existing" yaml:"files_existing"`
+	FilesFailed     int     `json:"files_failed" yaml:"files_failed"`
+	Bytes           int64   `json:"bytes" yaml:"bytes"`
+}
+
+func newRunEntry(r service.Run) runEntry {
+	return runEntry{
+		ID:              r.ID,
+		Command:         r.Command,
+		Status:          r.Status,
+		Selected:        r.Modules,
+		StartedAt:       r.StartedAt,
+		FinishedAt:      optionalTime(r.FinishedAt),
+		Seconds:         r.Duration().Seconds(),
+		Parsed:          r.Parsed,
+		Skipped:         r.Skipped,
+		Failed:          r.Failed,
+		FilesDownloaded: r.FilesDownloaded,
+		FilesExisting:   r.FilesExisting,
+		FilesFailed:     r.FilesFailed,
+		Bytes:           r.Bytes,
+	}
+}
+
+func (e runEntry) duration() string {
+	if e.FinishedAt == nil {
+		return "-"
+	}
+
+	return time.Duration(e.Seconds * float64(time.Second)).Round(time.Second).String()
+}
+
+func (e runEntry) csvRecord() []string {
+	finished := ""
+	if e.FinishedAt != nil {
+		finished = e.FinishedAt.Format(time.RFC3339)
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(e.ID), 10),
+		string(e.Status),
+		e.StartedAt.Format(time.RFC3339),
+		finished,
+		strconv.FormatFloat(e.Seconds, 'f', 3, 64),
+		strconv.Itoa(e.Parsed),
+		strconv.Itoa(e.Skipped),
+		strconv.Itoa(e.Failed),
+		strconv.Itoa(e.FilesDownloaded),
+		strconv.Itoa(e.FilesExisting),
+		strconv.Itoa(e.FilesFailed),
+		strconv.FormatInt(e.Bytes, 10),
+		e.Command,
+	}
+}
+
+var runCSVHeader = []string{
+	"id", "status", "started_at", "finished_at", "seconds", "parsed", "skipped", "failed",
+	"files_downloaded", "files_existing", "files_failed", "bytes", "command",
+}
+
+type runListReport struct {
+	Runs []runEntry `json:"runs" yaml:"runs"`
+}
+
+func (r *runListReport) writeTable(w io.Writer) {
+	fmt.Fprintln(w, "ID\tSTATUS\tSTARTED\tDURATION\tMODULES\tFILES\tSIZE\tCOMMAND")
+
+	for _, e := range r.Runs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d/%d/%d\t%d/%d/%d\t%s\t%s\n",
+			e.ID, e.Status, formatTime(e.StartedAt), e.duration(),
+			e.Parsed, e.Skipped, e.Failed,
+			e.FilesDownloaded, e.FilesExisting, e.FilesFailed,
+			formatBytes(e.Bytes), e.Command)
+	}
+}
+
+func (r *runListReport) csvRecords() [][]string {
+	records := [][]string{runCSVHeader}
+
+	for _, e := range r.Runs {
+		records = append(records, e.csvRecord())
+	}
+
+	return records
+}
+
+type runModuleEntry struct {
+	ModuleID        uint    `json:"module_id" yaml:"module_id"`
+	Name            string  `json:"name" yaml:"name"`
+	FilesDownloaded int     `json:"files_downloaded" yaml:"files_downloaded"`
+	FilesExisting   int     `json:"files_existing" yaml:"files_existing"`
+	FilesFailed     int     `json:"files_failed" yaml:"files_failed"`
+	FilesAdded      int     `json:"files_added" yaml:"files_added"`
+	FilesRemoved    int     `json:"files_removed" yaml:"files_removed"`
+	Bytes           int64   `json:"bytes" yaml:"bytes"`
+	Seconds         float64 `json:"seconds" yaml:"seconds"`
+	Error           string  `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type runShowReport struct {
+	runEntry `yaml:",inline"`
+
+	Modules []runModuleEntry `json:"modules" yaml:"modules"`
+}
+
+func (r *runShowReport) writeTable(w io.Writer) {
+	selected := "all"
+	if len(r.Selected) > 0 {
+		ids := make([]string, 0, len(r.Selected))
+		for _, id := range r.Selected {
+			ids = append(ids, strconv.FormatUint(uint64(id), 10))
+		}
+
+		selected = strings.Join(ids, ", ")
+	}
+
+	finished := time.Time{}
+	if r.FinishedAt != nil {
+		finished = *r.FinishedAt
+	}
+
+	fields := [][2]string{
+		{"ID", strconv.FormatUint(uint64(r.ID), 10)},
+		{"Command", r.Command},
+		{"Status", string(r.Status)},
+		{"Selected", selected},
+		{"Started", formatTime(r.StartedAt)},
+		{"Finished", formatTime(finished)},
+		{"Duration", r.duration()},
+		{"Modules", fmt.Sprintf("%d parsed, %d skipped, %d failed", r.Parsed, r.Skipped, r.Failed)},
+		{"Files", fmt.Sprintf("%d downloaded, %d existing, %d failed", r.FilesDownloaded, r.FilesExisting, r.FilesFailed)},
+		{"Downloaded", formatBytes(r.Bytes)},
+	}
+
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s:\t%s\n", f[0], f[1])
+	}
+
+	if len(r.Modules) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nMODULE\tDOWNLOADED\tEXISTING\tFAILED\tADDED\tREMOVED\tSIZE\tDURATION\tNAME")
+
+	for _, m := range r.Modules {
+		name := m.Name
+		if m.Error != "" {
+			name = "error: " + m.Error
+		}
+
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			m.ModuleID, m.FilesDownloaded, m.FilesExisting, m.FilesFailed, m.FilesAdded, m.FilesRemoved,
+			formatBytes(m.Bytes), time.Duration(m.Seconds*float64(time.Second)).Round(time.Millisecond), name)
+	}
+}
+
+func (r *runShowReport) csvRecords() [][]string {
+	records := [][]string{{
+		"module_id", "name", "files_downloaded", "files_existing", "files_failed",
+		"files_added", "files_removed", "bytes", "seconds", "error",
+	}}
+
+	for _, m := range r.Modules {
+		records = append(records, []string{
+			strconv.FormatUint(uint64(m.ModuleID), 10),
+			m.Name,
+			strconv.Itoa(m.FilesDownloaded),
+			strconv.Itoa(m.FilesExisting),
+			strconv.Itoa(m.FilesFailed),
+			strconv.Itoa(m.FilesAdded),
+			strconv.Itoa(m.FilesRemoved),
+			strconv.FormatInt(m.Bytes, 10),
+			strconv.FormatFloat(m.Seconds, 'f', 3, 64),
+			m.Error,
+		})
+	}
+
+	return records
+}
+
+// recordModule stores the result of a module and adds it to the run totals.
+func (be *backend) recordModule(ctx context.Context, run *service.Run, result service.RunModule) {
+	result.RunID = run.ID
+
+	err := be.storage.Runs().AddModules(ctx, result)
+	if err != nil {
+		be.logger.Error("recording module result", uberzap.Uint("run", run.ID), uberzap.Uint("id", result.ModuleID), uberzap.Error(err))
+	}
+
+	if result.Error != "" {
+		run.Failed++
+	}
+
+	run.FilesDownloaded += result.FilesDownloaded
+	run.FilesExisting += result.FilesExisting
+	run.FilesFailed += result.FilesFailed
+	run.Bytes += result.Bytes
+}
+
+// finishRun stores the final state of the run and writes it as JSON to
+// reportPath when set.
+func (be *backend) finishRun(ctx context.Context, run *service.Run, reportPath string) error {
+	run.FinishedAt = time.Now()
+
+	run.Status = service.RunStatusCompleted
+	if ctx.Err() != nil {
+		run.Status = service.RunStatusInterrupted
+	}
+
+	// an interrupted run is still recorded
+	ctx = context.WithoutCancel(ctx)
+
+	err := be.storage.Runs().Update(ctx, run)
+	if err != nil {
+		return fmt.Errorf("update run: %w", err)
+	}
+
+	be.logger.Info("run finished",
+		uberzap.Uint("run", run.ID),
+		uberzap.String("status", string(run.Status)),
+		uberzap.Int("failed", run.Failed),
+		uberzap.Int("downloaded", run.FilesDownloaded),
+		uberzap.Duration("duration", run.Duration()),
+	)
+
+	if reportPath == "" {
+		return nil
+	}
+
+	r, err := be.runShowReport(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(reportPath)
+	if err != nil {
+		return fmt.Errorf("create report: %w", err)
+	}
+	defer f.Close()
+
+	err = writeJSON(f, r)
+	if err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	return f.Close()
+}
+
+func (be *backend) runShowReport(ctx context.Context, id uint) (*runShowReport, error) {
+	run, err := be.storage.Runs().Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get run %d: %w", id, err)
+	}
+
+	results, err := be.storage.Runs().ListModules(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list run modules: %w", err)
+	}
+
+	r := &runShowReport{
+		runEntry: newRunEntry(*run),
+		Modules:  make([]runModuleEntry, 0, len(results)),
+	}
+
+	for _, m := range results {
+		r.Modules = append(r.Modules, runModuleEntry{
+			ModuleID:        m.ModuleID,
+			Name:            m.Name,
+			FilesDownloaded: m.FilesDownloaded,
+			FilesExisting:   m.FilesExisting,
+			FilesFailed:     m.FilesFailed,
+			FilesAdded:      m.FilesAdded,
+			FilesRemoved:    m.FilesRemoved,
+			Bytes:           m.Bytes,
+			Seconds:         m.Duration.Seconds(),
+			Error:           m.Error,
+		})
+	}
+
+	return r, nil
+}
+
+// RunsList prints the latest runs, newest first.
+func (be *backend) RunsList(ctx context.Context, limit int, output outputFormat) error {
+	runs, err := be.storage.Runs().List(ctx, limit)
+	if err != nil {
+		return fmt.Errorf("list runs: %w", err)
+	}
+
+	r := &runListReport{Runs: make([]runEntry, 0, len(runs))}
+	for _, run := range runs {
+		r.Runs = append(r.Runs, newRunEntry(run))
+	}
+
+	return writeReport(output, r)
+}
+
+// RunShow prints totals of the run and the result of every module it processed.
+func (be *backend) RunShow(ctx context.Context, id uint, output outputFormat) error {
+	r, err := be.runShowReport(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return writeReport(output, r)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ldmonster/tts-parser/internal/module"
+	"github.com/ldmonster/tts-parser/internal/script"
+
+	uberzap "go.uber.org/zap"
+)
+
+type scanScriptsOptions struct {
+	Clean  bool
+	Output string
+	Force  bool
+}
+
+type scriptReport struct {
+	ModuleID uint
+	GUID     string
+	Nickname string
+	Path     string
+	Findings []script.Finding
+}
+
+const globalScriptPath = "Global"
+
+// ScanScripts checks global and object scripts of the given modules, or of every
+// module stored in the DB, against the script rules and optionally writes cleaned
+// copies of infected saves.
+func (be *backend) ScanScripts(ctx context.Context, ids []uint, opts scanScriptsOptions) error {
+	ids, err := be.moduleIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	output := opts.Output
+	if output == "" {
+		output = be.cfg.TTS.SavesPath
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODULE\tGUID\tNICKNAME\tPATH\tRULES")
+
+	infected := 0
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		mod, err := module.DecodeFile(be.workshopFilePath(id))
+		if errors.Is(err, os.ErrNotExist) {
+			be.logger.Warn("workshop file is not found", uberzap.Uint("id", id))
+			continue
+		}
+
+		if err != nil {
+			be.logger.Warn("reading workshop module", uberzap.Uint("id", id), uberzap.Error(err))
+			continue
+		}
+
+		reports := scanModuleScripts(id, mod)
+		if len(reports) == 0 {
+			continue
+		}
+
+		infected++
+
+		for _, r := range reports {
+			rules := make([]string, 0, len(r.Findings))
+			for _, f := range r.Findings {
+				rules = append(rules, fmt.Sprintf("%s(%s)", f.Rule, f.Kind))
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", r.ModuleID, r.GUID, r.Nickname, r.Path, strings.Join(rules, ","))
+		}
+
+		if !opts.Clean {
+			continue
+		}
+
+		cleaned := cleanModuleScripts(mod)
+		if cleaned == 0 {
+			continue
+		}
+
+		target := filepath.Join(output, fmt.Sprintf("%d_clean.json", id))
+
+		err = writeSave(target, mod, opts.Force)
+		if err != nil {
+			return err
+		}
+
+		be.logger.Info("cleaned save written", uberzap.Uint("id", id), uberzap.String("save", target), uberzap.Int("scripts", cleaned))
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	be.logger.Info("scripts scanned", uberzap.Int("modules", len(ids)), uberzap.Int("infected", infected))
+
+	return nil
+}
+
+func scanModuleScripts(id uint, mod *module.Module) []scriptReport {
+	reports := make([]scriptReport, 0)
+
+	if findings := script.Scan(mod.LuaScript); len(findings) > 0 {
+		reports = append(reports, scriptReport{
+			ModuleID: id,
+			Path:     globalScriptPath,
+			Findings: findings,
+		})
+	}
+
+	mod.WalkObjects(func(path module.ObjectPath, obj *module.Object) {
+		findings := script.Scan(obj.LuaScript)
+		if len(findings) == 0 {
+			return
+		}
+
+		reports = append(reports, scriptReport{
+			ModuleID: id,
+			GUID:     obj.GUID,
+			Nickname: obj.Nickname,
+			Path:     path.String(),
+			Findings: findings,
+		})
+	})
+
+	return reports
+}
+
+// cleanModuleScripts strips known payloads in place and returns how many scripts changed.
+func cleanModuleScripts(mod *module.Module) int {
+	count := 0
+
+	if cleaned, ok := script.Clean(mod.LuaScript); ok {
+		mod.LuaScript = cleaned
+		count++
+	}
+
+	mod.WalkObjects(func(_ module.ObjectPath, obj *module.Object) {
+		if cleaned, ok := script.Clean(obj.LuaScript); ok {
+			obj.LuaScript = cleaned
+			count++
+		}
+	})
+
+	return count
+}
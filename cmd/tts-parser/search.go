@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+const (
+	// searchHitLimit bounds documents fetched for a query, they are grouped by module afterwards.
+	searchHitLimit = 1000
+	// searchMatchesPerModule bounds matching documents printed for a module.
+	searchMatchesPerModule = 5
+)
+
+type searchOptions struct {
+	// Limit is the number of modules printed.
+	Limit int
+	// All includes removed and purged modules.
+	All bool
+}
+
+type moduleSearchResult struct {
+	Module service.Module
+	// Score sums ranks of the matching documents.
+	Score float64
+	Hits  []service.SearchHit
+}
+
+// Search prints modules whose names, metadata, notes, objects or notebook
+// contain every word of the query, best matches first.
+func (be *backend) Search(ctx context.Context, query string, opts searchOptions) error {
+	hits, err := be.storage.Search().Search(ctx, query, searchHitLimit)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	mods, err := be.storage.Modules().List(ctx)
+	if err != nil {
+		return fmt.Errorf("list modules: %w", err)
+	}
+
+	known := make(map[uint]service.Module, len(mods))
+	for _, m := range mods {
+		known[m.ID] = m
+	}
+
+	byModule := make(map[uint]*moduleSearchResult)
+	results := make([]*moduleSearchResult, 0, 1)
+
+	for _, hit := range hits {
+		m, ok := known[hit.ModuleID]
+		if !ok || (!opts.All && m.Status != service.ModuleStatusActive) {
+			continue
+		}
+
+		res, ok := byModule[hit.ModuleID]
+		if !ok {
+			res = &moduleSearchResult{Module: m}
+			byModule[hit.ModuleID] = res
+			results = append(results, res)
+		}
+
+		res.Score += hit.Rank
+		res.Hits = append(res.Hits, hit)
+	}
+
+	slices.SortStableFunc(results, func(a, b *moduleSearchResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSCORE\tKIND\tMATCH\tPATH")
+
+	for _, res := range results {
+		for i, hit := range res.Hits {
+			if i == searchMatchesPerModule {
+				fmt.Fprintf(w, "\t\t\t\t(%d more)\t\n", len(res.Hits)-i)
+				break
+			}
+
+			if i == 0 {
+				fmt.Fprintf(w, "%d\t%s\t%.2f\t", res.Module.ID, res.Module.Name, res.Score)
+			} else {
+				fmt.Fprint(w, "\t\t\t")
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\n", hit.Kind, hit.Title, hit.Path)
+		}
+	}
+
+	return w.Flush()
+}
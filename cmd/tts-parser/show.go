@@ -0,0 +1,320 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+)
+
+type assetStatus string
+
+const (
+	// assetStatusStored files are stored and present in the asset store.
+	assetStatusStored assetStatus = "stored"
+	// assetStatusMissing files are stored but absent from the asset store.
+	assetStatusMissing assetStatus = "missing"
+	// assetStatusFailed files are referenced by the save but were never downloaded.
+	assetStatusFailed assetStatus = "failed"
+)
+
+// showSourcesInTable bounds source objects printed per asset in the table.
+const showSourcesInTable = 3
+
+type assetSource struct {
+	GUID     string `json:"guid,omitempty" yaml:"guid,omitempty"`
+	Nickname string `json:"nickname,omitempty" yaml:"nickname,omitempty"`
+	Path     string `json:"path" yaml:"path"`
+}
+
+func (s assetSource) String() string {
+	name := s.Nickname
+	if name == "" {
+		name = s.Path
+	}
+
+	if s.GUID == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s (%s)", name, s.GUID)
+}
+
+type assetEntry struct {
+	URL       string      `json:"url" yaml:"url"`
+	Extension string      `json:"extension,omitempty" yaml:"extension,omitempty"`
+	Status    assetStatus `json:"status" yaml:"status"`
+	Size      int64       `json:"size" yaml:"size"`
+	// Installed is set when the file is present in the TTS Mods directory.
+	Installed bool          `json:"installed" yaml:"installed"`
+	Sources   []assetSource `json:"sources,omitempty" yaml:"sources,omitempty"`
+}
+
+type assetGroup struct {
+	Folder string       `json:"folder" yaml:"folder"`
+	Bytes  int64        `json:"bytes" yaml:"bytes"`
+	Files  []assetEntry `json:"files" yaml:"files"`
+}
+
+type moduleShowReport struct {
+	ID             uint                 `json:"id" yaml:"id"`
+	Name           string               `json:"name" yaml:"name"`
+	Status         service.ModuleStatus `json:"status" yaml:"status"`
+	VersionNumber  string               `json:"version_number,omitempty" yaml:"version_number,omitempty"`
+	SavedAt        *time.Time           `json:"saved_at,omitempty" yaml:"saved_at,omitempty"`
+	GameMode       string               `json:"game_mode,omitempty" yaml:"game_mode,omitempty"`
+	GameType       string               `json:"game_type,omitempty" yaml:"game_type,omitempty"`
+	GameComplexity string               `json:"game_complexity,omitempty" yaml:"game_complexity,omitempty"`
+	MinPlayingTime int                  `json:"min_playing_time,omitempty" yaml:"min_playing_time,omitempty"`
+	MaxPlayingTime int                  `json:"max_playing_time,omitempty" yaml:"max_playing_time,omitempty"`
+	PlayerCounts   []int                `json:"player_counts,omitempty" yaml:"player_counts,omitempty"`
+	Tags           []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Table          string               `json:"table,omitempty" yaml:"table,omitempty"`
+	Sky            string               `json:"sky,omitempty" yaml:"sky,omitempty"`
+	Note           string               `json:"note,omitempty" yaml:"note,omitempty"`
+	LastSeenAt     *time.Time           `json:"last_seen_at,omitempty" yaml:"last_seen_at,omitempty"`
+	SyncedAt       *time.Time           `json:"synced_at,omitempty" yaml:"synced_at,omitempty"`
+	RemovedAt      *time.Time           `json:"removed_at,omitempty" yaml:"removed_at,omitempty"`
+	Files          int                  `json:"files" yaml:"files"`
+	Bytes          int64                `json:"bytes" yaml:"bytes"`
+	FailedFiles    int                  `json:"failed_files" yaml:"failed_files"`
+	Assets         []assetGroup         `json:"assets" yaml:"assets"`
+}
+
+func (r *moduleShowReport) writeTable(w io.Writer) {
+	deref := func(t *time.Time) time.Time {
+		if t == nil {
+			return time.Time{}
+		}
+
+		return *t
+	}
+
+	fields := [][2]string{
+		{"ID", strconv.FormatUint(uint64(r.ID), 10)},
+		{"Name", r.Name},
+		{"Status", string(r.Status)},
+		{"Version", r.VersionNumber},
+		{"Saved", formatTime(deref(r.SavedAt))},
+		{"Game mode", r.GameMode},
+		{"Game type", r.GameType},
+		{"Complexity", r.GameComplexity},
+		{"Playing time", formatPlayingTime(r.MinPlayingTime, r.MaxPlayingTime)},
+		{"Players", formatPlayerCounts(r.PlayerCounts)},
+		{"Tags", strings.Join(r.Tags, ", ")},
+		{"Table", r.Table},
+		{"Sky", r.Sky},
+		{"Last seen", formatTime(deref(r.LastSeenAt))},
+		{"Synced", formatTime(deref(r.SyncedAt))},
+		{"Removed", formatTime(deref(r.RemovedAt))},
+		{"Files", fmt.Sprintf("%d, %s", r.Files, formatBytes(r.Bytes))},
+		{"Failed", strconv.Itoa(r.FailedFiles)},
+	}
+
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s:\t%s\n", f[0], f[1])
+	}
+
+	if r.Note != "" {
+		fmt.Fprintf(w, "Note:\t%s\n", strings.ReplaceAll(r.Note, "\n", " "))
+	}
+
+	for _, g := range r.Assets {
+		fmt.Fprintf(w, "\n%s (%d, %s)\n", g.Folder, len(g.Files), formatBytes(g.Bytes))
+		fmt.Fprintln(w, "STATUS\tSIZE\tINSTALLED\tURL\tSOURCES")
+
+		for _, f := range g.Files {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Status, formatBytes(f.Size), formatInstalled(f.Installed), f.URL, formatSources(f.Sources))
+		}
+	}
+}
+
+func (r *moduleShowReport) csvRecords() [][]string {
+	records := [][]string{{"folder", "url", "extension", "status", "size", "installed", "sources"}}
+
+	for _, g := range r.Assets {
+		for _, f := range g.Files {
+			sources := make([]string, 0, len(f.Sources))
+			for _, s := range f.Sources {
+				sources = append(sources, s.GUID+":"+s.Path)
+			}
+
+			records = append(records, []string{
+				g.Folder,
+				f.URL,
+				f.Extension,
+				string(f.Status),
+				strconv.FormatInt(f.Size, 10),
+				strconv.FormatBool(f.Installed),
+				strings.Join(sources, ";"),
+			})
+		}
+	}
+
+	return records
+}
+
+func formatInstalled(installed bool) string {
+	if installed {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func formatSources(sources []assetSource) string {
+	if len(sources) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, showSourcesInTable+1)
+
+	for i, s := range sources {
+		if i == showSourcesInTable {
+			parts = append(parts, fmt.Sprintf("%d more", len(sources)-i))
+			break
+		}
+
+		parts = append(parts, s.String())
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Show prints metadata of the module and its assets grouped by type. Assets
+// referenced by the latest archived save but never downloaded are listed as
+// failed, sources are the objects of that save using the asset.
+func (be *backend) Show(ctx context.Context, id uint, output outputFormat) error {
+	m, err := be.storage.Modules().Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get module %d: %w", id, err)
+	}
+
+	files, err := be.storage.Files().ListByModuleID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+
+	r := &moduleShowReport{
+		ID:             m.ID,
+		Name:           m.Name,
+		Status:         m.Status,
+		VersionNumber:  m.VersionNumber,
+		GameMode:       m.GameMode,
+		GameType:       m.GameType,
+		GameComplexity: m.GameComplexity,
+		MinPlayingTime: m.MinPlayingTime,
+		MaxPlayingTime: m.MaxPlayingTime,
+		PlayerCounts:   m.PlayerCounts,
+		Tags:           m.Tags,
+		Table:          m.Table,
+		Sky:            m.Sky,
+		Note:           m.Note,
+		LastSeenAt:     optionalTime(m.LastSeenAt),
+		SyncedAt:       optionalTime(m.SyncedAt),
+		RemovedAt:      optionalTime(m.RemovedAt),
+		Files:          len(files),
+		FailedFiles:    m.FailedFiles,
+	}
+
+	if m.EpochTime > 0 {
+		r.SavedAt = optionalTime(time.Unix(int64(m.EpochTime), 0))
+	}
+
+	var (
+		referenced module.FileMapping[module.ModuleFile]
+		sources    map[string][]module.FileSource
+	)
+
+	save, err := be.latestArchivedSave(ctx, id)
+
+	switch {
+	case err == nil:
+		scanned := module.NewTTSModule()
+		scanned.ScanModule(save)
+
+		referenced = scanned.GetAll()
+		sources = save.FileSources()
+	case !errors.Is(err, service.ErrModuleVersionIsNotFound):
+		return fmt.Errorf("reading latest version: %w", err)
+	}
+
+	groups := make(map[service.FileType]*assetGroup)
+	stored := make(map[string]struct{}, len(files))
+
+	add := func(t service.FileType, entry assetEntry) {
+		for _, s := range sources[entry.URL] {
+			entry.Sources = append(entry.Sources, assetSource{GUID: s.GUID, Nickname: s.Nickname, Path: s.Path.String()})
+		}
+
+		g, ok := groups[t]
+		if !ok {
+			g = &assetGroup{Folder: module.ModuleFile{Type: t}.GetFolder()}
+			groups[t] = g
+		}
+
+		g.Bytes += entry.Size
+		g.Files = append(g.Files, entry)
+	}
+
+	for _, f := range files {
+		stored[f.URL] = struct{}{}
+
+		mf := module.ModuleFile{URL: f.URL, Type: f.Type, Extension: f.Extension}
+		entry := assetEntry{URL: f.URL, Extension: f.Extension, Status: assetStatusMissing, Size: f.Size}
+
+		if info, ok := downloader.Lookup(ctx, be.assets, mf); ok {
+			entry.Status = assetStatusStored
+			entry.Size = info.Size
+		}
+
+		entry.Installed = be.installed(mf)
+		r.Bytes += entry.Size
+
+		add(f.Type, entry)
+	}
+
+	// removed and purged modules keep only what was stored
+	if m.Status == service.ModuleStatusActive {
+		for url, mf := range referenced {
+			if _, ok := stored[url]; ok {
+				continue
+			}
+
+			add(mf.Type, assetEntry{URL: url, Extension: mf.GetExtension(), Status: assetStatusFailed})
+		}
+	}
+
+	for _, t := range slices.Sorted(maps.Keys(groups)) {
+		g := groups[t]
+		slices.SortFunc(g.Files, func(a, b assetEntry) int {
+			return cmp.Compare(a.URL, b.URL)
+		})
+
+		r.Assets = append(r.Assets, *g)
+	}
+
+	return writeReport(output, r)
+}
+
+// installed reports whether the file is present in the TTS Mods directory.
+func (be *backend) installed(mf module.ModuleFile) bool {
+	if be.cfg.TTS.ModsPath == "" || mf.GetExtension() == "" {
+		return false
+	}
+
+	_, err := os.Stat(downloader.InstallPath(be.cfg.TTS.ModsPath, mf))
+
+	return err == nil
+}
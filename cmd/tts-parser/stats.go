@@ -0,0 +1,475 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/assetstore"
+	"github.com/ldmonster/tts-parser/internal/downloader"
+	"github.com/ldmonster/tts-parser/internal/module"
+)
+
+// statsUntracked labels stored bytes no recorded run downloaded.
+const statsUntracked = "untracked"
+
+type statsOptions struct {
+	// Top is the number of modules and hosts printed.
+	Top    int
+	Output outputFormat
+}
+
+type statusStats struct {
+	Status  service.ModuleStatus `json:"status" yaml:"status"`
+	Modules int                  `json:"modules" yaml:"modules"`
+}
+
+type typeStats struct {
+	Folder string `json:"folder" yaml:"folder"`
+	Files  int    `json:"files" yaml:"files"`
+	Bytes  int64  `json:"bytes" yaml:"bytes"`
+}
+
+type moduleSizeStats struct {
+	ID    uint   `json:"id" yaml:"id"`
+	Name  string `json:"name" yaml:"name"`
+	Files int    `json:"files" yaml:"files"`
+	Bytes int64  `json:"bytes" yaml:"bytes"`
+}
+
+type hostStats struct {
+	Host   string `json:"host" yaml:"host"`
+	Files  int    `json:"files" yaml:"files"`
+	Bytes  int64  `json:"bytes" yaml:"bytes"`
+	Failed int    `json:"failed" yaml:"failed"`
+	// FailureRate is the share of failed downloads among the files of the host.
+	FailureRate float64 `json:"failure_rate" yaml:"failure_rate"`
+}
+
+type dedupeStats struct {
+	// SharedFiles are stored once and used by more than one module.
+	SharedFiles int `json:"shared_files" yaml:"shared_files"`
+	// DuplicateFiles have the same content as another stored file.
+	DuplicateFiles int `json:"duplicate_files" yaml:"duplicate_files"`
+	// StoredBytes counts every stored file once.
+	StoredBytes int64 `json:"stored_bytes" yaml:"stored_bytes"`
+	// UniqueBytes counts identical contents once.
+	UniqueBytes int64 `json:"unique_bytes" yaml:"unique_bytes"`
+	SavedBytes  int64 `json:"saved_bytes" yaml:"saved_bytes"`
+}
+
+type growthStats struct {
+	// Month is formatted as 2006-01, or untracked.
+	Month string `json:"month" yaml:"month"`
+	Files int    `json:"files" yaml:"files"`
+	Bytes int64  `json:"bytes" yaml:"bytes"`
+	// TotalBytes sums bytes downloaded up to the end of the month.
+	TotalBytes int64 `json:"total_bytes" yaml:"total_bytes"`
+}
+
+type statsReport struct {
+	Modules      int               `json:"modules" yaml:"modules"`
+	Statuses     []statusStats     `json:"statuses" yaml:"statuses"`
+	Files        int               `json:"files" yaml:"files"`
+	Bytes        int64             `json:"bytes" yaml:"bytes"`
+	Types        []typeStats       `json:"types" yaml:"types"`
+	Largest      []moduleSizeStats `json:"largest" yaml:"largest"`
+	TopHosts     []hostStats       `json:"top_hosts" yaml:"top_hosts"`
+	FailingHosts []hostStats       `json:"failing_hosts" yaml:"failing_hosts"`
+	Dedupe       dedupeStats       `json:"dedupe" yaml:"dedupe"`
+	Growth       []growthStats     `json:"growth" yaml:"growth"`
+}
+
+func (r *statsReport) writeTable(w io.Writer) {
+	statuses := make([]string, 0, len(r.Statuses))
+	for _, s := range r.Statuses {
+		statuses = append(statuses, fmt.Sprintf("%d %s", s.Modules, s.Status))
+	}
+
+	fmt.Fprintf(w, "Modules:\t%d (%s)\n", r.Modules, strings.Join(statuses, ", "))
+	fmt.Fprintf(w, "Files:\t%d, %s\n", r.Files, formatBytes(r.Bytes))
+	fmt.Fprintf(w, "Shared files:\t%d\n", r.Dedupe.SharedFiles)
+	fmt.Fprintf(w, "Duplicate content:\t%d files, %s of %s\n", r.Dedupe.DuplicateFiles, formatBytes(r.Dedupe.SavedBytes), formatBytes(r.Dedupe.StoredBytes))
+
+	fmt.Fprintln(w, "\nTYPE\tFILES\tSIZE")
+
+	for _, t := range r.Types {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", t.Folder, t.Files, formatBytes(t.Bytes))
+	}
+
+	fmt.Fprintln(w, "\nLARGEST\tFILES\tSIZE\tNAME")
+
+	for _, m := range r.Largest {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\n", m.ID, m.Files, formatBytes(m.Bytes), m.Name)
+	}
+
+	writeHosts := func(title string, hosts []hostStats) {
+		fmt.Fprintf(w, "\n%s\tFILES\tSIZE\tFAILED\tFAILURE RATE\n", title)
+
+		for _, h := range hosts {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%.1f%%\n", h.Host, h.Files, formatBytes(h.Bytes), h.Failed, h.FailureRate*100)
+		}
+	}
+
+	writeHosts("TOP HOSTS", r.TopHosts)
+	writeHosts("FAILING HOSTS", r.FailingHosts)
+
+	fmt.Fprintln(w, "\nMONTH\tFILES\tADDED\tTOTAL")
+
+	for _, g := range r.Growth {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", g.Month, g.Files, formatBytes(g.Bytes), formatBytes(g.TotalBytes))
+	}
+}
+
+func (r *statsReport) csvRecords() [][]string {
+	records := [][]string{{"section", "name", "count", "bytes", "failed", "failure_rate"}}
+
+	add := func(section, name string, count int, bytes int64, failed string, rate string) {
+		records = append(records, []string{section, name, strconv.Itoa(count), strconv.FormatInt(bytes, 10), failed, rate})
+	}
+
+	for _, s := range r.Statuses {
+		add("status", string(s.Status), s.Modules, 0, "", "")
+	}
+
+	for _, t := range r.Types {
+		add("type", t.Folder, t.Files, t.Bytes, "", "")
+	}
+
+	for _, m := range r.Largest {
+		add("largest", strconv.FormatUint(uint64(m.ID), 10), m.Files, m.Bytes, "", "")
+	}
+
+	addHosts := func(section string, hosts []hostStats) {
+		for _, h := range hosts {
+			add(section, h.Host, h.Files, h.Bytes, strconv.Itoa(h.Failed), strconv.FormatFloat(h.FailureRate, 'f', 4, 64))
+		}
+	}
+
+	addHosts("top_host", r.TopHosts)
+	addHosts("failing_host", r.FailingHosts)
+
+	add("dedupe", "shared", r.Dedupe.SharedFiles, 0, "", "")
+	add("dedupe", "stored", 0, r.Dedupe.StoredBytes, "", "")
+	add("dedupe", "unique", 0, r.Dedupe.UniqueBytes, "", "")
+	add("dedupe", "saved", r.Dedupe.DuplicateFiles, r.Dedupe.SavedBytes, "", "")
+
+	for _, g := range r.Growth {
+		add("growth", g.Month, g.Files, g.Bytes, "", "")
+	}
+
+	return records
+}
+
+// Stats prints the size of the library by module status and file type, the
+// largest modules, hosts with the most files and failures, files shared by
+// modules or stored twice with the same content and how many bytes runs
+// downloaded per month. Files shared by modules count once.
+func (be *backend) Stats(ctx context.Context, opts statsOptions) error {
+	mods, err := be.storage.Modules().List(ctx)
+	if err != nil {
+		return fmt.Errorf("list modules: %w", err)
+	}
+
+	files, err := be.storage.Files().List(ctx)
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+
+	failures, err := be.storage.FileFailures().List(ctx)
+	if err != nil {
+		return fmt.Errorf("list failures: %w", err)
+	}
+
+	runs, err := be.storage.Runs().List(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("list runs: %w", err)
+	}
+
+	r := &statsReport{Modules: len(mods)}
+
+	statuses := make(map[service.ModuleStatus]int)
+	for _, m := range mods {
+		statuses[m.Status]++
+	}
+
+	for _, status := range slices.Sorted(maps.Keys(statuses)) {
+		r.Statuses = append(r.Statuses, statusStats{Status: status, Modules: statuses[status]})
+	}
+
+	type storedFile struct {
+		file    service.File
+		modules int
+	}
+
+	// modules share a stored file when its key is the same
+	stored := make(map[string]*storedFile)
+	sizes := make(map[uint]*moduleSizeStats)
+
+	for _, f := range files {
+		key := downloader.Key(module.ModuleFile{URL: f.URL, Type: f.Type, Extension: f.Extension})
+
+		sf, ok := stored[key]
+		if !ok {
+			sf = &storedFile{file: f}
+			stored[key] = sf
+		}
+
+		sf.modules++
+		sf.file.Size = max(sf.file.Size, f.Size)
+
+		m, ok := sizes[f.ModuleID]
+		if !ok {
+			m = &moduleSizeStats{ID: f.ModuleID}
+			sizes[f.ModuleID] = m
+		}
+
+		m.Files++
+		m.Bytes += f.Size
+	}
+
+	types := make(map[service.FileType]*typeStats)
+	hosts := make(map[string]*hostStats)
+
+	host := func(u string) *hostStats {
+		name := "-"
+		if parsed, err := url.Parse(u); err == nil && parsed.Hostname() != "" {
+			name = strings.ToLower(parsed.Hostname())
+		}
+
+		h, ok := hosts[name]
+		if !ok {
+			h = &hostStats{Host: name}
+			hosts[name] = h
+		}
+
+		return h
+	}
+
+	for _, sf := range stored {
+		f := sf.file
+
+		r.Files++
+		r.Bytes += f.Size
+
+		t, ok := types[f.Type]
+		if !ok {
+			t = &typeStats{Folder: module.ModuleFile{Type: f.Type}.GetFolder()}
+			types[f.Type] = t
+		}
+
+		t.Files++
+		t.Bytes += f.Size
+
+		h := host(f.URL)
+		h.Files++
+		h.Bytes += f.Size
+
+		if sf.modules > 1 {
+			r.Dedupe.SharedFiles++
+		}
+	}
+
+	// a URL failing for several modules is one failed download
+	failed := make(map[string]struct{}, len(failures))
+	for _, f := range failures {
+		key := downloader.Key(module.ModuleFile{URL: f.URL, Type: f.Type})
+		if _, ok := failed[key]; ok {
+			continue
+		}
+
+		failed[key] = struct{}{}
+		host(f.URL).Failed++
+	}
+
+	for _, t := range slices.Sorted(maps.Keys(types)) {
+		r.Types = append(r.Types, *types[t])
+	}
+
+	names := make(map[uint]string, len(mods))
+	for _, m := range mods {
+		names[m.ID] = m.Name
+	}
+
+	for _, m := range sizes {
+		m.Name = names[m.ID]
+		r.Largest = append(r.Largest, *m)
+	}
+
+	slices.SortFunc(r.Largest, func(a, b moduleSizeStats) int {
+		return cmp.Or(cmp.Compare(b.Bytes, a.Bytes), cmp.Compare(a.ID, b.ID))
+	})
+
+	r.Largest = r.Largest[:min(opts.Top, len(r.Largest))]
+
+	for _, h := range hosts {
+		if total := h.Files + h.Failed; total > 0 {
+			h.FailureRate = float64(h.Failed) / float64(total)
+		}
+
+		r.TopHosts = append(r.TopHosts, *h)
+
+		if h.Failed > 0 {
+			r.FailingHosts = append(r.FailingHosts, *h)
+		}
+	}
+
+	slices.SortFunc(r.TopHosts, func(a, b hostStats) int {
+		return cmp.Or(cmp.Compare(b.Files, a.Files), cmp.Compare(a.Host, b.Host))
+	})
+
+	slices.SortFunc(r.FailingHosts, func(a, b hostStats) int {
+		return cmp.Or(cmp.Compare(b.FailureRate, a.FailureRate), cmp.Compare(b.Failed, a.Failed), cmp.Compare(a.Host, b.Host))
+	})
+
+	r.TopHosts = r.TopHosts[:min(opts.Top, len(r.TopHosts))]
+	r.FailingHosts = r.FailingHosts[:min(opts.Top, len(r.FailingHosts))]
+
+	storedFiles := make([]service.File, 0, len(stored))
+	for _, sf := range stored {
+		storedFiles = append(storedFiles, sf.file)
+	}
+
+	r.Dedupe.StoredBytes = r.Bytes
+
+	r.Dedupe.DuplicateFiles, r.Dedupe.SavedBytes, err = be.duplicateContent(ctx, storedFiles)
+	if err != nil {
+		return err
+	}
+
+	r.Dedupe.UniqueBytes = r.Dedupe.StoredBytes - r.Dedupe.SavedBytes
+
+	r.Growth = statsGrowth(runs, r.Bytes)
+
+	return writeReport(opts.Output, r)
+}
+
+// duplicateContent counts stored files with the content of another one and
+// the bytes they take. Only files of the same size are read and hashed.
+func (be *backend) duplicateContent(ctx context.Context, files []service.File) (int, int64, error) {
+	bySize := make(map[int64][]service.File)
+	for _, f := range files {
+		if f.Size > 0 {
+			bySize[f.Size] = append(bySize[f.Size], f)
+		}
+	}
+
+	var (
+		duplicates int
+		saved      int64
+	)
+
+	for size, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+
+		hashes := make(map[string]struct{}, len(candidates))
+
+		for _, f := range candidates {
+			hash, err := be.storedHash(ctx, module.ModuleFile{URL: f.URL, Type: f.Type, Extension: f.Extension})
+			if err != nil {
+				return 0, 0, err
+			}
+
+			if hash == "" {
+				continue
+			}
+
+			if _, ok := hashes[hash]; ok {
+				duplicates++
+				saved += size
+
+				continue
+			}
+
+			hashes[hash] = struct{}{}
+		}
+	}
+
+	return duplicates, saved, nil
+}
+
+// storedHash returns the SHA-256 of a stored file, empty when it is missing
+// from the store.
+func (be *backend) storedHash(ctx context.Context, mf module.ModuleFile) (string, error) {
+	info, ok := downloader.Lookup(ctx, be.assets, mf)
+	if !ok {
+		return "", nil
+	}
+
+	rc, err := be.assets.Get(ctx, info.Key)
+	if errors.Is(err, assetstore.ErrNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", info.Key, err)
+	}
+	defer rc.Close()
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, rc)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", info.Key, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// statsGrowth sums files and bytes downloaded by runs per month. Stored bytes
+// no run downloaded, e.g. adopted from a TTS cache or stored before runs were
+// recorded, are reported as untracked ahead of every month.
+func statsGrowth(runs []service.Run, storedBytes int64) []growthStats {
+	months := make(map[string]*growthStats)
+
+	var downloaded int64
+
+	for _, run := range runs {
+		if run.FilesDownloaded == 0 && run.Bytes == 0 {
+			continue
+		}
+
+		month := run.StartedAt.Local().Format("2006-01")
+
+		g, ok := months[month]
+		if !ok {
+			g = &growthStats{Month: month}
+			months[month] = g
+		}
+
+		g.Files += run.FilesDownloaded
+		g.Bytes += run.Bytes
+		downloaded += run.Bytes
+	}
+
+	growth := make([]growthStats, 0, len(months)+1)
+
+	var total int64
+
+	if untracked := storedBytes - downloaded; untracked > 0 {
+		total = untracked
+		growth = append(growth, growthStats{Month: statsUntracked, Bytes: untracked, TotalBytes: total})
+	}
+
+	for _, month := range slices.Sorted(maps.Keys(months)) {
+		g := months[month]
+		total += g.Bytes
+		g.TotalBytes = total
+
+		growth = append(growth, *g)
+	}
+
+	return growth
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+// Unpack explodes a workshop module into an editable directory tree.
+func (be *backend) Unpack(_ context.Context, id uint, dir string, force bool) error {
+	mod, err := module.DecodeFile(be.workshopFilePath(id))
+	if err != nil {
+		return fmt.Errorf("reading workshop module: %w", err)
+	}
+
+	if dir == "" {
+		dir = filepath.Join("unpacked", fmt.Sprintf("%d", id))
+	}
+
+	err = module.Unpack(mod, dir, force)
+	if err != nil {
+		return fmt.Errorf("unpack: %w", err)
+	}
+
+	be.logger.Info("module unpacked", uberzap.Uint("id", id), uberzap.String("dir", dir))
+
+	return nil
+}
+
+// Pack rebuilds a save from a directory tree produced by Unpack.
+func (be *backend) Pack(_ context.Context, dir string, output string, force bool) error {
+	mod, err := module.Pack(dir)
+	if err != nil {
+		return fmt.Errorf("pack: %w", err)
+	}
+
+	if output == "" {
+		output = filepath.Clean(dir) + ".json"
+	}
+
+	err = writeSave(output, mod, force)
+	if err != nil {
+		return err
+	}
+
+	be.logger.Info("module packed", uberzap.String("dir", dir), uberzap.String("save", output))
+
+	return nil
+}
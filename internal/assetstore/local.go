@@ -0,0 +1,188 @@
+package assetstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// tempPrefix names files of unfinished puts.
+const tempPrefix = ".put-"
+
+// Local keeps assets as files under a root directory.
+type Local struct {
+	root string
+}
+
+func NewLocal(root string) *Local {
+	return &Local{
+		root: root,
+	}
+}
+
+func (l *Local) Path(key string) string {
+	return filepath.Join(l.root, filepath.FromSlash(key))
+}
+
+func (l *Local) Stat(_ context.Context, key string) (Info, error) {
+	info, err := os.Stat(l.Path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Info{}, ErrNotFound
+	}
+
+	if err != nil {
+		return Info{}, err
+	}
+
+	return Info{
+		Key:     key,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// Put writes into a temporary file renamed into place, so an interrupted
+// download never leaves a truncated asset behind.
+func (l *Local) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	target := l.Path(key)
+
+	err := os.MkdirAll(filepath.Dir(target), 0o777)
+	if err != nil {
+		return fmt.Errorf("creating directories: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(target), tempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return fmt.Errorf("writing to file: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("closing file: %w", err)
+	}
+
+	err = os.Rename(f.Name(), target)
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("renaming file: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(l.Path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	err := os.Remove(l.Path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (l *Local) List(_ context.Context, prefix string) ([]Info, error) {
+	result := make([]Info, 0)
+
+	// walk the deepest directory covering the prefix
+	dir := path.Dir(prefix)
+	if strings.HasSuffix(prefix, "/") {
+		dir = strings.TrimSuffix(prefix, "/")
+	}
+
+	err := filepath.WalkDir(l.Path(dir), func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		// skip files of unfinished puts
+		if strings.HasPrefix(d.Name(), tempPrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		result = append(result, Info{
+			Key:     key,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(result, func(a, b Info) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return result, nil
+}
+
+// RemoveTemp removes files of puts interrupted by a killed process.
+func (l *Local) RemoveTemp() (int, error) {
+	removed := 0
+
+	err := filepath.WalkDir(l.root, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil || d.IsDir() || !strings.HasPrefix(d.Name(), tempPrefix) {
+			return err
+		}
+
+		err = os.Remove(p)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		removed++
+
+		return nil
+	})
+
+	return removed, err
+}
@@ -0,0 +1,94 @@
+package assetstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory keeps assets in memory, it stands in for a real store in tests and dry runs.
+type Memory struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		objects: make(map[string]memoryObject),
+	}
+}
+
+func (m *Memory) Stat(_ context.Context, key string) (Info, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	obj, ok := m.objects[key]
+	if !ok {
+		return Info{}, ErrNotFound
+	}
+
+	return Info{Key: key, Size: int64(len(obj.data)), ModTime: obj.modTime}, nil
+}
+
+func (m *Memory) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[key] = memoryObject{data: data, modTime: time.Now()}
+
+	return nil
+}
+
+func (m *Memory) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.objects, key)
+
+	return nil
+}
+
+func (m *Memory) List(_ context.Context, prefix string) ([]Info, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Info, 0)
+
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			result = append(result, Info{Key: key, Size: int64(len(obj.data)), ModTime: obj.modTime})
+		}
+	}
+
+	slices.SortFunc(result, func(a, b Info) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package assetstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Options struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// Prefix is prepended to every key, so one bucket may hold several stores.
+	Prefix string
+}
+
+// S3 keeps assets in an S3-compatible bucket, e.g. AWS S3 or MinIO.
+type S3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3(ctx context.Context, opts S3Options) (*S3, error) {
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure: opts.UseSSL,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("checking bucket: %w", err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("bucket %q does not exist", opts.Bucket)
+	}
+
+	prefix := strings.Trim(opts.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &S3{
+		client: client,
+		bucket: opts.Bucket,
+		prefix: prefix,
+	}, nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (Info, error) {
+	obj, err := s.client.StatObject(ctx, s.bucket, s.prefix+key, minio.StatObjectOptions{})
+	if err != nil {
+		return Info{}, s.mapError(err)
+	}
+
+	return Info{
+		Key:     key,
+		Size:    obj.Size,
+		ModTime: obj.LastModified,
+	}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.prefix+key, r, size, minio.PutObjectOptions{})
+
+	return err
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.prefix+key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.mapError(err)
+	}
+
+	// GetObject is lazy, stat surfaces a missing object
+	_, err = obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, s.mapError(err)
+	}
+
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, s.prefix+key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3) List(ctx context.Context, prefix string) ([]Info, error) {
+	result := make([]Info, 0)
+
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    s.prefix + prefix,
+		Recursive: true,
+	}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+
+		result = append(result, Info{
+			Key:     strings.TrimPrefix(obj.Key, s.prefix),
+			Size:    obj.Size,
+			ModTime: obj.LastModified,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *S3) mapError(err error) error {
+	resp := minio.ToErrorResponse(err)
+	if resp.StatusCode == http.StatusNotFound || resp.Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,45 @@
+package assetstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("asset is not found")
+
+// Info describes a stored asset. Keys are slash separated paths laid out like
+// the TTS Mods directory, e.g. Images/<name>.png.
+type Info struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Store keeps downloaded assets.
+type Store interface {
+	// Stat returns ErrNotFound when there is no asset under key.
+	Stat(ctx context.Context, key string) (Info, error)
+	// Put stores r under key, size is -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get returns ErrNotFound when there is no asset under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the asset, deleting a missing asset is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns assets whose key starts with prefix, ordered by key.
+	List(ctx context.Context, prefix string) ([]Info, error)
+}
+
+// TempRemover is implemented by stores leaving partial files behind when the
+// process is killed in the middle of a put.
+type TempRemover interface {
+	// RemoveTemp returns the number of removed files.
+	RemoveTemp() (int, error)
+}
+
+// Pather is implemented by stores keeping assets as local files, those may be
+// linked or referenced by path instead of being copied.
+type Pather interface {
+	Path(key string) string
+}
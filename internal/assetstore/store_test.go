@@ -0,0 +1,276 @@
+package assetstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStore checks the behaviour every Store has to provide.
+func testStore(t *testing.T, newStore func(t *testing.T) Store) {
+	ctx := context.Background()
+
+	t.Run("PutGet", func(t *testing.T) {
+		s := newStore(t)
+
+		err := s.Put(ctx, "Images/a.png", strings.NewReader("image"), 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := read(t, s, "Images/a.png"); got != "image" {
+			t.Errorf("Get = %q, want %q", got, "image")
+		}
+
+		err = s.Put(ctx, "Images/a.png", strings.NewReader("replaced"), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := read(t, s, "Images/a.png"); got != "replaced" {
+			t.Errorf("Get after overwrite = %q, want %q", got, "replaced")
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		s := newStore(t)
+
+		_, err := s.Stat(ctx, "Models/missing.obj")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat of a missing asset = %v, want ErrNotFound", err)
+		}
+
+		err = s.Put(ctx, "Models/m.obj", strings.NewReader("mesh"), 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := s.Stat(ctx, "Models/m.obj")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Key != "Models/m.obj" || info.Size != 4 || info.ModTime.IsZero() {
+			t.Errorf("Stat = %+v", info)
+		}
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		s := newStore(t)
+
+		_, err := s.Get(ctx, "Images/missing.png")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get of a missing asset = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		s := newStore(t)
+
+		err := s.Put(ctx, "Images/a.png", strings.NewReader("image"), 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = s.Delete(ctx, "Images/a.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = s.Stat(ctx, "Images/a.png")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat after Delete = %v, want ErrNotFound", err)
+		}
+
+		err = s.Delete(ctx, "Images/a.png")
+		if err != nil {
+			t.Errorf("Delete of a missing asset = %v, want nil", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		s := newStore(t)
+
+		for _, key := range []string{"Images/b.png", "Images/a.png", "Models/m.obj"} {
+			err := s.Put(ctx, key, strings.NewReader(key), int64(len(key)))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		infos, err := s.List(ctx, "Images/")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var keys []string
+		for _, info := range infos {
+			keys = append(keys, info.Key)
+		}
+
+		if got, want := strings.Join(keys, ","), "Images/a.png,Images/b.png"; got != want {
+			t.Errorf("List = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("FailedPut", func(t *testing.T) {
+		s := newStore(t)
+
+		err := s.Put(ctx, "Images/a.png", strings.NewReader("image"), 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = s.Put(ctx, "Images/a.png", failingReader{}, -1)
+		if err == nil {
+			t.Fatal("Put of a failing reader succeeded")
+		}
+
+		err = s.Put(ctx, "Images/new.png", failingReader{}, -1)
+		if err == nil {
+			t.Fatal("Put of a failing reader succeeded")
+		}
+
+		if got := read(t, s, "Images/a.png"); got != "image" {
+			t.Errorf("Get after a failed overwrite = %q, want %q", got, "image")
+		}
+
+		_, err = s.Stat(ctx, "Images/new.png")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat after a failed put = %v, want ErrNotFound", err)
+		}
+
+		infos, err := s.List(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(infos) != 1 {
+			t.Errorf("List after failed puts = %+v, want only Images/a.png", infos)
+		}
+	})
+}
+
+func TestLocal(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		return NewLocal(t.TempDir())
+	})
+}
+
+func TestMemory(t *testing.T) {
+	testStore(t, func(t *testing.T) Store {
+		return NewMemory()
+	})
+}
+
+// TestS3 runs against the bucket named by TEST_S3_ENDPOINT, TEST_S3_BUCKET,
+// TEST_S3_ACCESS_KEY and TEST_S3_SECRET_KEY, e.g. of a local MinIO.
+func TestS3(t *testing.T) {
+	endpoint := os.Getenv("TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_S3_ENDPOINT is not set")
+	}
+
+	testStore(t, func(t *testing.T) Store {
+		s, err := NewS3(context.Background(), S3Options{
+			Endpoint:  endpoint,
+			Bucket:    os.Getenv("TEST_S3_BUCKET"),
+			AccessKey: os.Getenv("TEST_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("TEST_S3_SECRET_KEY"),
+			UseSSL:    os.Getenv("TEST_S3_USE_SSL") == "true",
+			Prefix:    fmt.Sprintf("test-%d/", time.Now().UnixNano()),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	})
+}
+
+func TestLocalRemoveTemp(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s := NewLocal(root)
+
+	err := s.Put(ctx, "Images/a.png", strings.NewReader("image"), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Put(ctx, "Images/b.png", failingReader{}, -1)
+	if err == nil {
+		t.Fatal("Put of a failing reader succeeded")
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, "Images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("files after a failed put = %v, want the temporary file removed", entries)
+	}
+
+	// a put of a killed process
+	err = os.WriteFile(filepath.Join(root, "Images", tempPrefix+"123"), []byte("partial"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := s.List(ctx, "Images/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(infos) != 1 {
+		t.Errorf("List = %+v, want temporary files skipped", infos)
+	}
+
+	removed, err := s.RemoveTemp()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != 1 {
+		t.Errorf("RemoveTemp = %d, want 1", removed)
+	}
+
+	entries, err = os.ReadDir(filepath.Join(root, "Images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Name() != "a.png" {
+		t.Errorf("files left = %v, want only a.png", entries)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func read(t *testing.T, s Store, key string) string {
+	t.Helper()
+
+	r, err := s.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
@@ -8,54 +8,126 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"net"
 	"net/http"
-	"os"
+	"net/url"
 	"path"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"sync"
+	"time"
 
 	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/assetstore"
 	"github.com/ldmonster/tts-parser/internal/module"
 
 	"github.com/gabriel-vasile/mimetype"
 	uberzap "go.uber.org/zap"
 )
 
-func NewClient(logger *uberzap.Logger) *Client {
+// DefaultPath is the directory assets are stored into, laid out like the TTS mods folder.
+const DefaultPath = "tmp/"
+
+// errNotModified is returned by a conditional download of an unchanged file.
+var errNotModified = errors.New("not modified")
+
+// errReadTimeout cancels a download whose body stalled longer than the read timeout.
+var errReadTimeout = errors.New("read timeout")
+
+// NewClient creates a client keeping files in store and installing them into
+// every TTS Mods directory of the install paths.
+func NewClient(logger *uberzap.Logger, store assetstore.Store, opts Options) *Client {
+	if opts.Scheduler == nil {
+		opts.Scheduler = NewScheduler(opts)
+	}
+
 	return &Client{
-		client:                 &http.Client{},
-		path:                   "tmp/",
-		maxConcurrentDownloads: 3,
-		errorCh:                make(chan error, 3),
+		client:                 newHTTPClient(opts),
+		store:                  store,
+		installPaths:           opts.InstallPaths,
+		opts:                   opts,
+		maxConcurrentDownloads: cmp.Or(opts.Concurrency, 3),
 		logger:                 logger,
 	}
 }
 
 type Client struct {
-	client *http.Client
-	path   string
+	client       *http.Client
+	store        assetstore.Store
+	installPaths []string
+	opts         Options
 
 	maxConcurrentDownloads int
 
-	errorCh chan error
-	logger  *uberzap.Logger
+	logger *uberzap.Logger
 }
 
-func (c *Client) DownloadModule(ctx context.Context, mod *module.TTSModule) []service.File {
-	downloadCh := make(chan struct{}, c.maxConcurrentDownloads)
-	downloadedModules := make([]service.File, 0, 1)
-	wg := new(sync.WaitGroup)
+type Failure struct {
+	File service.File
+	Err  error
+}
+
+// Result of a module download. Files are reported with the extension they are stored with.
+type Result struct {
+	Downloaded []service.File
+	Existing   []service.File
+	Failed     []Failure
+	// Installed counts copies made into install paths.
+	Installed int
+
+	mu sync.Mutex
+}
+
+// Available returns files present in the store after the download.
+func (r *Result) Available() []service.File {
+	return slices.Concat(r.Downloaded, r.Existing)
+}
+
+func (r *Result) addDownloaded(f service.File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Downloaded = append(r.Downloaded, f)
+}
+
+func (r *Result) addExisting(f service.File) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Existing = append(r.Existing, f)
+}
+
+func (r *Result) addInstalled(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Installed += n
+}
+
+func (r *Result) addFailed(f service.File, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Failed = append(r.Failed, Failure{File: f, Err: err})
+}
+
+// DownloadModule downloads files of the module missing from the store, known
+// are files stored by the previous sync of the module.
+func (c *Client) DownloadModule(ctx context.Context, mod *module.TTSModule, known ...service.File) *Result {
+	result := new(Result)
 
 	// Sort module files by URL for consistent ordering
 	files := c.getSortedModuleFiles(mod)
 
-	// Start download worker goroutine
-	go c.downloadWorker(ctx, files, downloadCh, wg, mod, &downloadedModules)
+	etags := make(map[string]string, len(known))
+	for _, f := range known {
+		etags[f.URL] = f.ETag
+	}
+
+	c.downloadWorker(ctx, files, mod, etags, result)
 
-	// Wait for downloads and handle errors
-	return c.waitForDownloads(ctx, downloadedModules)
+	return result
 }
 
 func (c *Client) getSortedModuleFiles(mod *module.TTSModule) []module.ModuleFile {
@@ -66,14 +138,19 @@ func (c *Client) getSortedModuleFiles(mod *module.TTSModule) []module.ModuleFile
 	return files
 }
 
-func (c *Client) downloadWorker(ctx context.Context, files []module.ModuleFile, downloadCh chan struct{}, wg *sync.WaitGroup, mod *module.TTSModule, downloadedModules *[]service.File) {
-	defer close(c.errorCh)
+func (c *Client) downloadWorker(ctx context.Context, files []module.ModuleFile, mod *module.TTSModule, etags map[string]string, result *Result) {
+	downloadCh := make(chan struct{}, c.maxConcurrentDownloads)
+	wg := new(sync.WaitGroup)
 
 	for _, mf := range files {
 		if mf.URL == "" {
 			continue
 		}
 
+		if ctx.Err() != nil {
+			break
+		}
+
 		downloadCh <- struct{}{}
 		wg.Add(1)
 
@@ -83,114 +160,352 @@ func (c *Client) downloadWorker(ctx context.Context, files []module.ModuleFile,
 				wg.Done()
 			}()
 
-			if c.fileExists(&mf) {
-				c.logger.Info("file already exists", uberzap.String("url", mf.URL))
+			var stored *service.File
+
+			if info, ok := c.fileExists(ctx, &mf); ok {
+				existing := serviceFile(mod.ID, mf, info.Size, etags[mf.URL])
+				existing.CreatedAt = info.ModTime
+
+				if !c.refresh(ctx, mf) {
+					c.logger.Debug("file already exists", uberzap.String("url", mf.URL))
+					result.addExisting(existing)
+					c.install(ctx, mf, result)
+
+					return
+				}
+
+				stored = &existing
+			}
+
+			size, etag, err := c.downloadWithRetries(ctx, &mf, stored)
+			if errors.Is(err, errNotModified) {
+				c.logger.Debug("file is not modified", uberzap.String("url", mf.URL))
+				result.addExisting(*stored)
+				c.install(ctx, mf, result)
+
+				return
+			}
+
+			// the stored copy is kept when it could not be refreshed
+			if err != nil && stored != nil {
+				c.logger.Warn("refresh file", uberzap.String("url", mf.URL), uberzap.Error(err))
+				result.addExisting(*stored)
+				c.install(ctx, mf, result)
+
 				return
 			}
 
-			if err := c.download(ctx, &mf); err != nil {
-				c.errorCh <- fmt.Errorf("url: %s download: %w", mf.URL, err)
+			if err != nil {
+				c.logger.Warn("download file", uberzap.String("url", mf.URL), uberzap.Error(err))
+				result.addFailed(serviceFile(mod.ID, mf, 0, ""), err)
 				return
 			}
 
 			c.logger.Info("downloaded", uberzap.String("url", mf.URL))
 
-			*downloadedModules = append(*downloadedModules, service.File{
-				ModuleID:  mod.ID,
-				Type:      mf.Type,
-				URL:       mf.URL,
-				Extension: mf.GetExtension(),
-			})
+			result.addDownloaded(serviceFile(mod.ID, mf, size, etag))
+			c.install(ctx, mf, result)
 		}(mf)
 	}
 
 	wg.Wait()
 }
 
-func (c *Client) waitForDownloads(ctx context.Context, downloadedModules []service.File) []service.File {
-	for {
+func (c *Client) install(ctx context.Context, mf module.ModuleFile, result *Result) {
+	n, err := c.Install(ctx, mf)
+	if err != nil {
+		c.logger.Warn("install file", uberzap.String("url", mf.URL), uberzap.Error(err))
+	}
+
+	result.addInstalled(n)
+}
+
+func serviceFile(moduleID uint, mf module.ModuleFile, size int64, etag string) service.File {
+	return service.File{
+		ModuleID:  moduleID,
+		Type:      mf.Type,
+		URL:       mf.URL,
+		Extension: mf.GetExtension(),
+		Size:      size,
+		ETag:      etag,
+	}
+}
+
+// refresh reports whether a file present in the store is downloaded again.
+// Changed files are detected by the download itself.
+func (c *Client) refresh(ctx context.Context, mf module.ModuleFile) bool {
+	switch c.opts.Overwrite {
+	case OverwriteAlways, OverwriteIfChanged:
+		return true
+	case OverwriteIfInvalid:
+		r, err := c.store.Get(ctx, Key(mf))
+		if err != nil {
+			return true
+		}
+		defer r.Close()
+
+		err = Validate(r, mf.Type)
+		if err != nil {
+			c.logger.Info("stored file is invalid", uberzap.String("url", mf.URL), uberzap.Error(err))
+			return true
+		}
+
+		return false
+	default:
+		return false
+	}
+}
+
+// fileExists looks the file up in the store, for images stored under an
+// unknown extension the extension found in the store is set on mf.
+func (c *Client) fileExists(ctx context.Context, mf *module.ModuleFile) (assetstore.Info, bool) {
+	info, ok := Lookup(ctx, c.store, *mf)
+	if ok && mf.GetExtension() == "" {
+		mf.Extension = path.Ext(info.Key)
+	}
+
+	return info, ok
+}
+
+// Key returns the key of the file in an asset store.
+func Key(mf module.ModuleFile) string {
+	return filepath.ToSlash(mf.GetRelativePath())
+}
+
+// Lookup finds the file in the store. Images are stored with a detected
+// extension, so when it is unknown any extension is accepted.
+func Lookup(ctx context.Context, store assetstore.Store, mf module.ModuleFile) (assetstore.Info, bool) {
+	if mf.GetExtension() != "" {
+		info, err := store.Stat(ctx, Key(mf))
+		return info, err == nil
+	}
+
+	infos, err := store.List(ctx, Key(mf)+".")
+	if err != nil || len(infos) == 0 {
+		return assetstore.Info{}, false
+	}
+
+	return infos[0], true
+}
+
+var googleSignInRegex = regexp.MustCompile(`^accounts.google.com$`)
+
+// statusError is a response other than 200 OK.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status code: %d", e.code)
+}
+
+// retryable reports whether a download failed by err may succeed when repeated.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusTooManyRequests || se.code >= http.StatusInternalServerError
+	}
+
+	// an unknown host stays unknown
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+
+	var ne net.Error
+
+	return errors.Is(err, errReadTimeout) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &ne)
+}
+
+// downloadWithRetries repeats downloads failed by the network or the server
+// with a doubling delay.
+func (c *Client) downloadWithRetries(ctx context.Context, mf *module.ModuleFile, stored *service.File) (int64, string, error) {
+	delay := c.opts.RetryDelay
+
+	for attempt := 1; ; attempt++ {
+		size, etag, err := c.scheduledDownload(ctx, mf, stored)
+		if err == nil || attempt > c.opts.Retries || !retryable(ctx, err) {
+			return size, etag, err
+		}
+
+		c.logger.Debug("retry download", uberzap.String("url", mf.URL), uberzap.Int("attempt", attempt), uberzap.Error(err))
+
 		select {
-		case err, ok := <-c.errorCh:
-			if !ok {
-				return downloadedModules
-			}
-			c.logger.Warn("download file", uberzap.Error(err))
 		case <-ctx.Done():
-			return downloadedModules
+			return 0, "", err
+		case <-time.After(delay):
 		}
+
+		delay *= 2
 	}
 }
 
-func (c *Client) fileExists(mf *module.ModuleFile) bool {
-	abs, err := filepath.Abs(filepath.Join(c.path, mf.GetFolder(), mf.GetFilename()) + mf.GetExtension())
+// scheduledDownload waits for the scheduler to allow a request to the host of the file.
+func (c *Client) scheduledDownload(ctx context.Context, mf *module.ModuleFile, stored *service.File) (int64, string, error) {
+	u, err := url.Parse(mf.URL)
+	if err != nil {
+		return 0, "", fmt.Errorf("parse url: %w", err)
+	}
+
+	release, err := c.opts.Scheduler.acquire(ctx, u.Hostname())
 	if err != nil {
-		c.logger.Warn("absolute path", uberzap.Error(err))
+		return 0, "", err
 	}
+	defer release()
 
-	_, err = os.Stat(abs)
-	return err == nil
+	return c.download(ctx, mf, stored)
 }
 
-var googleSignInRegex = regexp.MustCompile(`^accounts.google.com$`)
+// download stores the file and returns its size and ETag. With the overwrite
+// policy if-changed a stored file is kept and errNotModified returned when the
+// server reports it unchanged by its ETag or modification time.
+func (c *Client) download(ctx context.Context, mf *module.ModuleFile, stored *service.File) (int64, string, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
 
-func (c *Client) download(ctx context.Context, mf *module.ModuleFile) error {
 	// `https://steamusercontent-a.akamaihd.net/ugc/929306232365497323/03A7F5D6C7E7BC387121E8C444A9751CD81CCC9C/`
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mf.URL, nil)
 	if err != nil {
-		return fmt.Errorf("new request: %w", err)
+		return 0, "", fmt.Errorf("new request: %w", err)
 	}
 
-	req.Header.Set("user-agent", "curl/7.84.0")
+	req.Header.Set("user-agent", cmp.Or(c.opts.UserAgent, DefaultUserAgent))
 	req.Header.Set("accept", "*/*")
 
-	resp, err := http.DefaultClient.Do(req)
+	if r, ok := c.opts.hostRule(req.URL.Hostname()); ok {
+		if r.UserAgent != "" {
+			req.Header.Set("user-agent", r.UserAgent)
+		}
+
+		for k, v := range r.Headers {
+			req.Header.Set(k, v)
+		}
+	}
+
+	conditional := stored != nil && c.opts.Overwrite == OverwriteIfChanged
+	if conditional && stored.ETag != "" {
+		req.Header.Set("if-none-match", stored.ETag)
+	}
+
+	if conditional && !stored.CreatedAt.IsZero() {
+		req.Header.Set("if-modified-since", stored.CreatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := c.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("do: %w", err)
+		return 0, "", fmt.Errorf("do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	etag := resp.Header.Get("etag")
+
+	if conditional && unchanged(resp, etag, *stored) {
+		return 0, "", errNotModified
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("status code: %d", resp.StatusCode)
+		return 0, "", &statusError{code: resp.StatusCode}
 	}
 
 	if googleSignInRegex.MatchString(resp.Request.URL.Host) {
-		return errors.New("google 403")
+		return 0, "", errors.New("google 403")
 	}
 
-	body := io.Reader(resp.Body)
+	body := c.opts.Scheduler.throttle(ctx, resp.Body)
+
+	if c.opts.ReadTimeout > 0 {
+		timer := time.AfterFunc(c.opts.ReadTimeout, func() {
+			cancel(errReadTimeout)
+		})
+		defer timer.Stop()
+
+		body = &idleReader{r: body, timer: timer, timeout: c.opts.ReadTimeout}
+	}
 
 	if mf.GetExtension() == "" {
 		mtype, recycledBody, err := detectMimeType(body)
 		if err != nil {
-			return fmt.Errorf("detecting mime type: %w", err)
+			return 0, "", fmt.Errorf("detecting mime type: %w", readError(ctx, err))
 		}
 		body = recycledBody
 		mf.Extension = mtype.Extension()
 	}
 
-	filename := filepath.Join(c.path, mf.GetFolder(), mf.GetFilename()) + mf.GetExtension()
-	if err := saveFile(body, filename); err != nil {
-		return fmt.Errorf("saving file: %w", err)
+	counter := &countingReader{r: body}
+
+	if err := c.store.Put(ctx, Key(*mf), counter, resp.ContentLength); err != nil {
+		return 0, "", fmt.Errorf("saving file: %w", readError(ctx, err))
 	}
 
-	return nil
+	return counter.n, etag, nil
 }
 
-func saveFile(body io.Reader, filepath string) error {
-	if err := os.MkdirAll(path.Dir(filepath), 0o777); err != nil {
-		return fmt.Errorf("creating directories: %w", err)
+// unchanged reports whether the response is for the stored file content. A
+// server ignoring the conditional request is trusted by its validators, the
+// ETag or the modification time. A matching size alone proves nothing.
+func unchanged(resp *http.Response, etag string, stored service.File) bool {
+	if resp.StatusCode == http.StatusNotModified {
+		return true
 	}
 
-	file, err := os.OpenFile(filepath, os.O_RDWR|os.O_CREATE, 0o777)
-	if err != nil {
-		return fmt.Errorf("opening file: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	if etag != "" && stored.ETag != "" {
+		return etag == stored.ETag
 	}
-	defer file.Close()
 
-	if _, err := file.ReadFrom(body); err != nil {
-		return fmt.Errorf("writing to file: %w", err)
+	if resp.ContentLength >= 0 && resp.ContentLength != stored.Size {
+		return false
 	}
 
-	return nil
+	modified, err := http.ParseTime(resp.Header.Get("last-modified"))
+	if err != nil || stored.CreatedAt.IsZero() {
+		return false
+	}
+
+	// Last-Modified has a precision of seconds
+	return !modified.After(stored.CreatedAt.Truncate(time.Second))
+}
+
+// readError reports a stalled body instead of the cancellation it caused.
+func readError(ctx context.Context, err error) error {
+	if errors.Is(context.Cause(ctx), errReadTimeout) {
+		return errReadTimeout
+	}
+
+	return err
+}
+
+// idleReader postpones the read timeout on every read.
+type idleReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (ir *idleReader) Read(p []byte) (int, error) {
+	n, err := ir.r.Read(p)
+	ir.timer.Reset(ir.timeout)
+
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+
+	return n, err
 }
 
 func detectMimeType(input io.Reader) (*mimetype.MIME, io.Reader, error) {
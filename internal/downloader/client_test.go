@@ -0,0 +1,255 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/assetstore"
+	"github.com/ldmonster/tts-parser/internal/module"
+
+	uberzap "go.uber.org/zap"
+)
+
+const (
+	storedModel = "v 0 0 0\n"
+	servedModel = "v 1 1 1\n"
+)
+
+func TestDownloadOverwrite(t *testing.T) {
+	serve := func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, servedModel)
+	}
+
+	tests := []struct {
+		name      string
+		overwrite OverwritePolicy
+		stored    string
+		etag      string
+		handler   http.HandlerFunc
+		// downloaded or existing
+		want         string
+		wantRequests int32
+		wantContent  string
+	}{
+		{
+			name:         "nothing stored",
+			overwrite:    OverwriteNever,
+			handler:      serve,
+			want:         "downloaded",
+			wantRequests: 1,
+			wantContent:  servedModel,
+		},
+		{
+			name:        "never",
+			overwrite:   OverwriteNever,
+			stored:      storedModel,
+			handler:     serve,
+			want:        "existing",
+			wantContent: storedModel,
+		},
+		{
+			name:         "always",
+			overwrite:    OverwriteAlways,
+			stored:       storedModel,
+			handler:      serve,
+			want:         "downloaded",
+			wantRequests: 1,
+			wantContent:  servedModel,
+		},
+		{
+			name:      "if-changed etag not modified",
+			overwrite: OverwriteIfChanged,
+			stored:    storedModel,
+			etag:      `"a"`,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("if-none-match") == `"a"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				serve(w, r)
+			},
+			want:         "existing",
+			wantRequests: 1,
+			wantContent:  storedModel,
+		},
+		{
+			name:      "if-changed etag changed",
+			overwrite: OverwriteIfChanged,
+			stored:    storedModel,
+			etag:      `"a"`,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("etag", `"b"`)
+				serve(w, r)
+			},
+			want:         "downloaded",
+			wantRequests: 1,
+			wantContent:  servedModel,
+		},
+		{
+			name:      "if-changed modified since",
+			overwrite: OverwriteIfChanged,
+			stored:    storedModel,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("if-modified-since") != "" {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				serve(w, r)
+			},
+			want:         "existing",
+			wantRequests: 1,
+			wantContent:  storedModel,
+		},
+		{
+			name:      "if-changed old last-modified",
+			overwrite: OverwriteIfChanged,
+			stored:    storedModel,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("last-modified", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+				serve(w, r)
+			},
+			want:         "existing",
+			wantRequests: 1,
+			wantContent:  storedModel,
+		},
+		{
+			name:      "if-changed new last-modified",
+			overwrite: OverwriteIfChanged,
+			stored:    storedModel,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("last-modified", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+				serve(w, r)
+			},
+			want:         "downloaded",
+			wantRequests: 1,
+			wantContent:  servedModel,
+		},
+		{
+			name:         "if-changed same size without validators",
+			overwrite:    OverwriteIfChanged,
+			stored:       storedModel,
+			handler:      serve,
+			want:         "downloaded",
+			wantRequests: 1,
+			wantContent:  servedModel,
+		},
+		{
+			name:        "if-invalid valid",
+			overwrite:   OverwriteIfInvalid,
+			stored:      storedModel,
+			handler:     serve,
+			want:        "existing",
+			wantContent: storedModel,
+		},
+		{
+			name:         "if-invalid invalid",
+			overwrite:    OverwriteIfInvalid,
+			stored:       "<!DOCTYPE html><html><body>error</body></html>",
+			handler:      serve,
+			want:         "downloaded",
+			wantRequests: 1,
+			wantContent:  servedModel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				tt.handler(w, r)
+			}))
+			defer srv.Close()
+
+			ctx := context.Background()
+			store := assetstore.NewMemory()
+
+			mod := module.NewTTSModule()
+			mod.AddModel(srv.URL + "/model.obj")
+			mf := mod.Models[srv.URL+"/model.obj"]
+
+			if tt.stored != "" {
+				err := store.Put(ctx, Key(mf), strings.NewReader(tt.stored), int64(len(tt.stored)))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var known []service.File
+			if tt.etag != "" {
+				known = append(known, service.File{URL: mf.URL, ETag: tt.etag})
+			}
+
+			c := NewClient(uberzap.NewNop(), store, Options{Overwrite: tt.overwrite})
+			result := c.DownloadModule(ctx, mod, known...)
+
+			if len(result.Failed) > 0 {
+				t.Fatalf("failed: %v", result.Failed[0].Err)
+			}
+
+			got := "existing"
+			if len(result.Downloaded) > 0 {
+				got = "downloaded"
+			}
+
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", n, tt.wantRequests)
+			}
+
+			r, err := store.Get(ctx, Key(mf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			content, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != tt.wantContent {
+				t.Errorf("stored %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestDownloadReadTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-length", "100")
+		io.WriteString(w, "v 0")
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	mod := module.NewTTSModule()
+	mod.AddModel(srv.URL + "/model.obj")
+
+	c := NewClient(uberzap.NewNop(), assetstore.NewMemory(), Options{ReadTimeout: 50 * time.Millisecond})
+	result := c.DownloadModule(context.Background(), mod)
+
+	if len(result.Failed) != 1 {
+		t.Fatalf("failed %d files, want 1", len(result.Failed))
+	}
+
+	if err := result.Failed[0].Err; !errors.Is(err, errReadTimeout) {
+		t.Errorf("err = %v, want %v", err, errReadTimeout)
+	}
+}
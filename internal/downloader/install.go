@@ -0,0 +1,267 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ldmonster/tts-parser/internal/assetstore"
+	"github.com/ldmonster/tts-parser/internal/module"
+)
+
+// installExtensions maps extensions detected on download to the ones TTS
+// writes into its cache.
+var installExtensions = map[string]string{
+	".jpeg": ".jpg",
+}
+
+// InstallPath returns where TTS expects the file inside the Mods directory
+// root: the type folder, the name derived from the URL and the extension TTS
+// uses for the type. For images and audio the extension of the stored file
+// is kept, they are cached with the extension of their content.
+func InstallPath(root string, mf module.ModuleFile) string {
+	ext := mf.GetExtension()
+	if mapped, ok := installExtensions[strings.ToLower(ext)]; ok {
+		ext = mapped
+	}
+
+	return filepath.Join(root, mf.GetFolder(), mf.GetFilename()+ext)
+}
+
+// Install places the stored file into every install root laid out like the
+// TTS Mods directory and returns how many copies were made. The extension of
+// mf must be known.
+func (c *Client) Install(ctx context.Context, mf module.ModuleFile) (int, error) {
+	if len(c.installPaths) == 0 {
+		return 0, nil
+	}
+
+	if _, ok := Lookup(ctx, c.store, mf); !ok {
+		return 0, fmt.Errorf("file is not stored: %s", mf.URL)
+	}
+
+	installed := 0
+
+	for _, root := range c.installPaths {
+		copied, err := Export(ctx, c.store, Key(mf), InstallPath(root, mf), true)
+		if err != nil {
+			return installed, fmt.Errorf("installing into %s: %w", root, err)
+		}
+
+		if copied {
+			installed++
+		}
+	}
+
+	return installed, nil
+}
+
+// Export writes the stored asset to dst unless dst is up to date, and reports
+// whether it did. Assets of a local store are hardlinked when link is set.
+// Copies of other stores are up to date when they have the size of the asset
+// and were written after it.
+func Export(ctx context.Context, store assetstore.Store, key, dst string, link bool) (bool, error) {
+	if p, ok := store.(assetstore.Pather); ok {
+		return CopyFile(p.Path(key), dst, link)
+	}
+
+	info, err := store.Stat(ctx, key)
+	if errors.Is(err, assetstore.ErrNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if dstInfo, err := os.Stat(dst); err == nil && dstInfo.Size() == info.Size && !dstInfo.ModTime().Before(info.ModTime) {
+		return false, nil
+	}
+
+	r, err := store.Get(ctx, key)
+	if errors.Is(err, assetstore.ErrNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+
+	err = writeFile(dst, r)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Import stores src under key unless the key exists, and reports whether it
+// did. Files are hardlinked into a local store when link is set.
+func Import(ctx context.Context, store assetstore.Store, src, key string, link bool) (bool, error) {
+	if _, err := store.Stat(ctx, key); err == nil {
+		return false, nil
+	}
+
+	if p, ok := store.(assetstore.Pather); ok {
+		return CopyFile(src, p.Path(key), link)
+	}
+
+	f, err := os.Open(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	err = store.Put(ctx, key, f, info.Size())
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CopyFile hardlinks, when link is set and possible, or copies src to dst unless
+// dst has the content of src already, and reports whether it did. A dst that is
+// stale, e.g. after src was downloaded again, is replaced.
+func CopyFile(src, dst string, link bool) (bool, error) {
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	same, err := sameContent(src, dst)
+	if err != nil {
+		return false, err
+	}
+
+	if same {
+		return false, nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), 0o777)
+	if err != nil {
+		return false, fmt.Errorf("creating directories: %w", err)
+	}
+
+	// links of the previous src would keep its old content, replace them
+	err = os.Remove(dst)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	// hardlinks fail across devices, fall back to copying
+	if link && os.Link(src, dst) == nil {
+		return true, nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return false, err
+	}
+	defer in.Close()
+
+	err = writeFile(dst, in)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// sameContent reports whether dst exists and is src itself or has its size and
+// bytes.
+func sameContent(src, dst string) (bool, error) {
+	dstInfo, err := os.Stat(dst)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return false, err
+	}
+
+	if os.SameFile(srcInfo, dstInfo) {
+		return true, nil
+	}
+
+	if srcInfo.Size() != dstInfo.Size() {
+		return false, nil
+	}
+
+	a, err := fileHash(src)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := fileHash(dst)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(a, b), nil
+}
+
+func fileHash(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+func writeFile(dst string, r io.Reader) error {
+	err := os.MkdirAll(filepath.Dir(dst), 0o777)
+	if err != nil {
+		return fmt.Errorf("creating directories: %w", err)
+	}
+
+	// a hardlinked dst would be overwritten in place together with its origin
+	err = os.Remove(dst)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, r)
+	if err != nil {
+		out.Close()
+		os.Remove(dst)
+
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,139 @@
+package downloader
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OverwritePolicy decides whether files present in the store are downloaded again.
+type OverwritePolicy string
+
+const (
+	OverwriteNever  OverwritePolicy = "never"
+	OverwriteAlways OverwritePolicy = "always"
+	// OverwriteIfChanged downloads files the server reports as changed by
+	// their ETag or modification time, files without either are downloaded
+	// again.
+	OverwriteIfChanged OverwritePolicy = "if-changed"
+	// OverwriteIfInvalid downloads files whose stored content does not match their type.
+	OverwriteIfInvalid OverwritePolicy = "if-invalid"
+)
+
+func ParseOverwritePolicy(s string) (OverwritePolicy, error) {
+	switch p := OverwritePolicy(s); p {
+	case OverwriteNever, OverwriteAlways, OverwriteIfChanged, OverwriteIfInvalid:
+		return p, nil
+	case "":
+		return OverwriteNever, nil
+	default:
+		return "", fmt.Errorf("unknown overwrite policy %q, expected never, always, if-changed or if-invalid", s)
+	}
+}
+
+// DefaultUserAgent is sent unless configured otherwise, some hosts refuse
+// requests of browsers without cookies.
+const DefaultUserAgent = "curl/7.84.0"
+
+// HostRule overrides request settings for a host and its subdomains.
+type HostRule struct {
+	Host string
+	// UserAgent replaces the client one when set.
+	UserAgent string
+	// Proxy replaces the client one when set.
+	Proxy   *url.URL
+	Headers map[string]string
+	// Concurrency and Rate replace the host limits of the scheduler when set,
+	// hosts matching the rule share them.
+	Concurrency int
+	Rate        float64
+}
+
+func (r HostRule) matches(host string) bool {
+	host = strings.ToLower(host)
+	pattern := strings.ToLower(r.Host)
+
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// Options of a Client, zero timeouts disable them.
+type Options struct {
+	// InstallPaths are TTS Mods directories files are installed into.
+	InstallPaths []string
+	Overwrite    OverwritePolicy
+	// ConnectTimeout bounds dialing and the TLS handshake of a request.
+	ConnectTimeout time.Duration
+	// ReadTimeout bounds waiting for the response headers and for every read
+	// of the body, a slow but progressing download is not interrupted.
+	ReadTimeout time.Duration
+
+	// Concurrency is the number of files of a module downloaded at once, 3 when zero.
+	Concurrency int
+	// Retries of a download failed by the network or a 429 or 5xx response,
+	// RetryDelay doubles after every attempt.
+	Retries    int
+	RetryDelay time.Duration
+
+	// UserAgent is DefaultUserAgent when empty.
+	UserAgent string
+	// Proxy is used for every request, proxies of the environment when nil.
+	Proxy *url.URL
+	// Hosts are matched in order, the first matching rule applies.
+	Hosts []HostRule
+
+	// HostConcurrency caps requests in flight to a host and HostRate the
+	// requests started per second, zero disables them.
+	HostConcurrency int
+	HostRate        float64
+	// Bandwidth caps bytes per second read by all downloads, only within
+	// BandwidthHours when set. Zero disables it.
+	Bandwidth      int64
+	BandwidthHours TimeWindow
+
+	// Scheduler is shared by clients of concurrent modules, a client creates
+	// its own when nil.
+	Scheduler *Scheduler
+}
+
+func (opts Options) hostRule(host string) (HostRule, bool) {
+	for _, r := range opts.Hosts {
+		if r.matches(host) {
+			return r, true
+		}
+	}
+
+	return HostRule{}, false
+}
+
+func newHTTPClient(opts Options) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.ConnectTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   opts.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}
+
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = opts.ConnectTimeout
+	}
+
+	transport.ResponseHeaderTimeout = opts.ReadTimeout
+
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if r, ok := opts.hostRule(req.URL.Hostname()); ok && r.Proxy != nil {
+			return r.Proxy, nil
+		}
+
+		if opts.Proxy != nil {
+			return opts.Proxy, nil
+		}
+
+		return http.ProxyFromEnvironment(req)
+	}
+
+	return &http.Client{Transport: transport}
+}
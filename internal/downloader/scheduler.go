@@ -0,0 +1,250 @@
+package downloader
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheduler limits requests of every client sharing it per host and caps the
+// bandwidth of all their downloads, so modules may be downloaded at once
+// without hammering a host.
+type Scheduler struct {
+	opts Options
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+
+	// bandwidth is nil without a bandwidth cap
+	bandwidth *tokenBucket
+}
+
+type hostLimit struct {
+	// slots is nil without a concurrency cap
+	slots chan struct{}
+	// rate is nil without a rate limit
+	rate *tokenBucket
+}
+
+func NewScheduler(opts Options) *Scheduler {
+	s := &Scheduler{
+		opts:  opts,
+		hosts: make(map[string]*hostLimit),
+	}
+
+	if opts.Bandwidth > 0 {
+		s.bandwidth = newTokenBucket(float64(opts.Bandwidth), float64(opts.Bandwidth))
+	}
+
+	return s
+}
+
+// acquire waits for a request slot of the host and its rate limit, release
+// returns the slot.
+func (s *Scheduler) acquire(ctx context.Context, host string) (release func(), err error) {
+	l := s.hostLimit(host)
+
+	release = func() {}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		release = func() { <-l.slots }
+	}
+
+	if l.rate != nil {
+		err = l.rate.wait(ctx, 1)
+		if err != nil {
+			release()
+
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// hostLimit returns limits of the host. Hosts matching a rule with limits
+// share them, e.g. every CDN node of steamusercontent.com.
+func (s *Scheduler) hostLimit(host string) *hostLimit {
+	key := strings.ToLower(host)
+	concurrency, rate := s.opts.HostConcurrency, s.opts.HostRate
+
+	if r, ok := s.opts.hostRule(host); ok && (r.Concurrency > 0 || r.Rate > 0) {
+		key = "rule " + strings.ToLower(r.Host)
+		concurrency = cmp.Or(r.Concurrency, concurrency)
+		rate = cmp.Or(r.Rate, rate)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.hosts[key]
+	if ok {
+		return l
+	}
+
+	l = &hostLimit{}
+
+	if concurrency > 0 {
+		l.slots = make(chan struct{}, concurrency)
+	}
+
+	if rate > 0 {
+		// a second worth of requests may start at once
+		l.rate = newTokenBucket(rate, math.Max(1, math.Floor(rate)))
+	}
+
+	s.hosts[key] = l
+
+	return l
+}
+
+// throttle caps reads of r by the bandwidth of the scheduler.
+func (s *Scheduler) throttle(ctx context.Context, r io.Reader) io.Reader {
+	if s.bandwidth == nil {
+		return r
+	}
+
+	return &throttledReader{
+		r:      r,
+		ctx:    ctx,
+		bucket: s.bandwidth,
+		window: s.opts.BandwidthHours,
+		// small reads keep waits for the bucket well below the read timeout
+		chunk: max(s.opts.Bandwidth/8, 1024),
+	}
+}
+
+type throttledReader struct {
+	r      io.Reader
+	ctx    context.Context
+	bucket *tokenBucket
+	window TimeWindow
+	chunk  int64
+}
+
+func (tr *throttledReader) Read(p []byte) (int, error) {
+	if !tr.window.Contains(time.Now()) {
+		return tr.r.Read(p)
+	}
+
+	if int64(len(p)) > tr.chunk {
+		p = p[:tr.chunk]
+	}
+
+	n, err := tr.r.Read(p)
+	if n > 0 {
+		werr := tr.bucket.wait(tr.ctx, float64(n))
+		if werr != nil {
+			return n, werr
+		}
+	}
+
+	return n, err
+}
+
+// tokenBucket refills rate tokens per second up to burst tokens.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst float64) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait takes n tokens, waiting until the bucket refilled them. Waiters are
+// served in order, each one reserves its tokens before waiting.
+func (b *tokenBucket) wait(ctx context.Context, n float64) error {
+	b.mu.Lock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= n
+
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// TimeWindow is a daily period of local time, it may span midnight. The zero
+// window is the whole day.
+type TimeWindow struct {
+	// Start and End are offsets from midnight.
+	Start, End time.Duration
+}
+
+// ParseTimeWindow parses windows like 08:00-20:00, an empty one is the whole day.
+func ParseTimeWindow(s string) (TimeWindow, error) {
+	if s == "" {
+		return TimeWindow{}, nil
+	}
+
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return TimeWindow{}, fmt.Errorf("time window %q is not like 08:00-20:00", s)
+	}
+
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("time window %q: %w", s, err)
+	}
+
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return TimeWindow{}, fmt.Errorf("time window %q: %w", s, err)
+	}
+
+	return TimeWindow{
+		Start: time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		End:   time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+	}, nil
+}
+
+// Contains reports whether t is within the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	if w.Start == w.End {
+		return true
+	}
+
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+
+	return offset >= w.Start || offset < w.End
+}
@@ -0,0 +1,217 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketWait(t *testing.T) {
+	tests := []struct {
+		name        string
+		rate, burst float64
+		takes       []float64
+		cancel      bool
+		want        time.Duration
+		wantErr     error
+	}{
+		{
+			name:  "within burst",
+			rate:  100,
+			burst: 10,
+			takes: []float64{4, 6},
+			want:  0,
+		},
+		{
+			name:  "refill",
+			rate:  100,
+			burst: 10,
+			takes: []float64{10, 5},
+			want:  50 * time.Millisecond,
+		},
+		{
+			name:  "more than burst",
+			rate:  100,
+			burst: 10,
+			takes: []float64{20},
+			want:  100 * time.Millisecond,
+		},
+		{
+			name:  "waiters in order",
+			rate:  100,
+			burst: 1,
+			takes: []float64{1, 5, 5},
+			want:  100 * time.Millisecond,
+		},
+		{
+			name:    "cancelled",
+			rate:    1,
+			burst:   1,
+			takes:   []float64{1, 10},
+			cancel:  true,
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if tt.cancel {
+				cancel()
+			}
+
+			b := newTokenBucket(tt.rate, tt.burst)
+			start := time.Now()
+
+			var err error
+			for _, n := range tt.takes {
+				err = b.wait(ctx, n)
+				if err != nil {
+					break
+				}
+			}
+
+			elapsed := time.Since(start)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if elapsed < tt.want-5*time.Millisecond || elapsed > tt.want+100*time.Millisecond {
+				t.Errorf("waited %v, want %v", elapsed, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeWindow(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    TimeWindow
+		wantErr bool
+	}{
+		{in: "", want: TimeWindow{}},
+		{in: "08:00-20:00", want: TimeWindow{Start: 8 * time.Hour, End: 20 * time.Hour}},
+		{in: "22:30 - 06:15", want: TimeWindow{Start: 22*time.Hour + 30*time.Minute, End: 6*time.Hour + 15*time.Minute}},
+		{in: "08:00", wantErr: true},
+		{in: "8-20", wantErr: true},
+		{in: "08:00-24:00", wantErr: true},
+		{in: "08:60-20:00", wantErr: true},
+		{in: "morning-20:00", wantErr: true},
+		{in: "08:00-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTimeWindow(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeWindowContains(t *testing.T) {
+	at := func(hour, minute, second int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, second, 0, time.Local)
+	}
+
+	day := TimeWindow{Start: 8 * time.Hour, End: 20 * time.Hour}
+	night := TimeWindow{Start: 22 * time.Hour, End: 6 * time.Hour}
+
+	tests := []struct {
+		name   string
+		window TimeWindow
+		t      time.Time
+		want   bool
+	}{
+		{name: "whole day", window: TimeWindow{}, t: at(3, 0, 0), want: true},
+		{name: "day start", window: day, t: at(8, 0, 0), want: true},
+		{name: "day within", window: day, t: at(12, 30, 0), want: true},
+		{name: "day before", window: day, t: at(7, 59, 59), want: false},
+		{name: "day end", window: day, t: at(20, 0, 0), want: false},
+		{name: "night start", window: night, t: at(22, 0, 0), want: true},
+		{name: "night before midnight", window: night, t: at(23, 59, 59), want: true},
+		{name: "night midnight", window: night, t: at(0, 0, 0), want: true},
+		{name: "night after midnight", window: night, t: at(5, 59, 59), want: true},
+		{name: "night end", window: night, t: at(6, 0, 0), want: false},
+		{name: "night noon", window: night, t: at(12, 0, 0), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Contains(tt.t); got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.t.Format("15:04:05"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostRule(t *testing.T) {
+	opts := Options{
+		Hosts: []HostRule{
+			{Host: "cloud-3.steamusercontent.com", UserAgent: "cloud-3"},
+			{Host: "Steamusercontent.com", UserAgent: "steam"},
+			{Host: "example.com", UserAgent: "example"},
+		},
+	}
+
+	tests := []struct {
+		host   string
+		want   string
+		wantOK bool
+	}{
+		{host: "example.com", want: "example", wantOK: true},
+		{host: "EXAMPLE.com", want: "example", wantOK: true},
+		{host: "cdn.example.com", want: "example", wantOK: true},
+		{host: "notexample.com", wantOK: false},
+		{host: "com", wantOK: false},
+		{host: "steamusercontent-a.akamaihd.net", wantOK: false},
+		{host: "steamusercontent.com", want: "steam", wantOK: true},
+		{host: "cloud-3.steamusercontent.com", want: "cloud-3", wantOK: true},
+		{host: "a.cloud-3.steamusercontent.com", want: "cloud-3", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			r, ok := opts.hostRule(tt.host)
+			if ok != tt.wantOK || r.UserAgent != tt.want {
+				t.Errorf("hostRule(%q) = %q, %v, want %q, %v", tt.host, r.UserAgent, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSchedulerHostLimit(t *testing.T) {
+	s := NewScheduler(Options{
+		HostConcurrency: 4,
+		Hosts:           []HostRule{{Host: "steamusercontent.com", Concurrency: 2}},
+	})
+
+	a := s.hostLimit("a.steamusercontent.com")
+	b := s.hostLimit("B.steamusercontent.com")
+
+	if a != b {
+		t.Error("hosts of a rule with limits do not share them")
+	}
+
+	if cap(a.slots) != 2 {
+		t.Errorf("rule concurrency = %d, want 2", cap(a.slots))
+	}
+
+	c := s.hostLimit("example.com")
+	if c == s.hostLimit("example.org") {
+		t.Error("hosts without a rule share limits")
+	}
+
+	if cap(c.slots) != 4 {
+		t.Errorf("host concurrency = %d, want 4", cap(c.slots))
+	}
+}
@@ -0,0 +1,56 @@
+package downloader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// Validate checks the head of the asset content against the type, rejecting
+// e.g. HTML error pages served instead of the asset.
+func Validate(r io.Reader, t service.FileType) error {
+	header := make([]byte, 3072)
+
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			return errors.New("empty file")
+		}
+
+		return err
+	}
+
+	header = header[:n]
+	mime := mimetype.Detect(header)
+
+	switch t {
+	case service.FileTypeAsset:
+		if !bytes.HasPrefix(header, []byte("Unity")) {
+			return fmt.Errorf("not an asset bundle: %s", mime)
+		}
+	case service.FileTypeImage:
+		if !strings.HasPrefix(mime.String(), "image/") {
+			return fmt.Errorf("not an image: %s", mime)
+		}
+	case service.FileTypePDF:
+		if !mime.Is("application/pdf") {
+			return fmt.Errorf("not a pdf: %s", mime)
+		}
+	case service.FileTypeAudio:
+		if !strings.HasPrefix(mime.String(), "audio/") && !mime.Is("application/ogg") {
+			return fmt.Errorf("not an audio: %s", mime)
+		}
+	case service.FileTypeModel:
+		if mime.Is("text/html") || !strings.HasPrefix(mime.String(), "text/") {
+			return fmt.Errorf("not a model: %s", mime)
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -27,4 +28,29 @@ type File struct {
 	Type      FileType
 	URL       string
 	Extension string
+	// Size in bytes of the stored file, zero when unknown.
+	Size int64
+	// ETag the server returned for the stored file, empty when unknown.
+	ETag string
+
+	// CreatedAt is when the file was stored, zero for files stored before it was tracked.
+	CreatedAt time.Time
+}
+
+// FileFailure is a file of a module which could not be downloaded on the last sync.
+type FileFailure struct {
+	ID uint
+
+	ModuleID uint
+	Type     FileType
+	URL      string
+	Error    string
+	FailedAt time.Time
+}
+
+// ModuleFileStats sums up files stored for a module.
+type ModuleFileStats struct {
+	ModuleID uint
+	Files    int
+	Bytes    int64
 }
@@ -2,13 +2,24 @@ package internal
 
 import (
 	"errors"
-
-	"github.com/Masterminds/semver/v3"
+	"time"
 )
 
 var (
-	ErrModuleConflict   = errors.New("module already exists")
-	ErrModuleIsNotFound = errors.New("module is not found")
+	ErrModuleConflict          = errors.New("module already exists")
+	ErrModuleIsNotFound        = errors.New("module is not found")
+	ErrModuleVersionIsNotFound = errors.New("module version is not found")
+)
+
+type ModuleStatus string
+
+const (
+	// ModuleStatusActive modules have a file in the Workshop directory.
+	ModuleStatusActive ModuleStatus = "active"
+	// ModuleStatusRemoved modules were unsubscribed or taken down, their files are kept.
+	ModuleStatusRemoved ModuleStatus = "removed"
+	// ModuleStatusPurged modules were removed and their files released for garbage collection.
+	ModuleStatusPurged ModuleStatus = "purged"
 )
 
 type Module struct {
@@ -16,5 +27,64 @@ type Module struct {
 
 	Name          string
 	EpochTime     uint
-	VersionNumber *semver.Version
+	VersionNumber string
+
+	// SaveEpochTime is the EpochTime written by TTS into the save.
+	SaveEpochTime int
+
+	// Workshop file state of the last parse, unchanged files are skipped.
+	WorkshopSize    int64
+	WorkshopModTime time.Time
+	WorkshopHash    string
+
+	Status     ModuleStatus
+	LastSeenAt time.Time
+	RemovedAt  time.Time
+
+	// SyncedAt is when files of the module were last downloaded.
+	SyncedAt time.Time
+	// FailedFiles counts referenced files which could not be downloaded on the last sync.
+	FailedFiles int
+
+	GameMode       string
+	GameType       string
+	GameComplexity string
+	// MinPlayingTime and MaxPlayingTime are minutes, zero when unknown.
+	MinPlayingTime int
+	MaxPlayingTime int
+	// PlayerCounts lists every supported number of players.
+	PlayerCounts []int
+	Tags         []string
+	Table        string
+	Sky          string
+	Note         string
+}
+
+// ModuleFilter selects modules matching every set field.
+type ModuleFilter struct {
+	// Statuses are the allowed statuses, any when empty.
+	Statuses []ModuleStatus
+	Players  int
+	// Tags must all be set on the module, they are compared case-insensitively.
+	Tags []string
+	// MaxPlayingTime excludes modules lasting longer or of unknown length.
+	MaxPlayingTime int
+	GameType       string
+	GameComplexity string
+}
+
+// ModuleVersion is a distinct workshop JSON of a module, Data is gzip-compressed.
+type ModuleVersion struct {
+	ID uint
+
+	ModuleID      uint
+	Hash          string
+	SaveName      string
+	VersionNumber string
+	EpochTime     uint
+	SaveEpochTime int
+	Size          int64
+	Data          []byte
+
+	CreatedAt time.Time
 }
@@ -0,0 +1,72 @@
+package module
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Decode reads a TTS save (workshop JSON or Saves entry) into the typed model.
+func Decode(r io.Reader) (*Module, error) {
+	mod := new(Module)
+
+	err := json.NewDecoder(r).Decode(mod)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	return mod, nil
+}
+
+// DecodeFile is Decode over a file on disk.
+func DecodeFile(path string) (*Module, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+
+	return Decode(f)
+}
+
+// Encode writes the save indented the way TTS does, without HTML escaping.
+func Encode(w io.Writer, mod *Module) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(mod)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	return nil
+}
+
+// Marshal is Encode into a byte slice.
+func Marshal(mod *Module) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	err := Encode(buf, mod)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Normalize brings any JSON document to a canonical form: compact, keys sorted,
+// numbers in shortest float form. Two saves that differ only in formatting
+// normalize to the same bytes.
+func Normalize(data []byte) ([]byte, error) {
+	var doc any
+
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return marshalNoEscape(doc)
+}
@@ -0,0 +1,114 @@
+package module
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRoundTrip decodes the saves of testdata and encodes them again, the
+// result must normalize to the same bytes as the original save.
+func TestRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) == 0 {
+		t.Fatal("no saves in testdata")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mod, err := Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			encoded, err := Marshal(mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := Normalize(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Normalize(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("round-trip changed the save\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+// TestEncodeStable encodes a decoded save twice, the bytes must not change.
+func TestEncodeStable(t *testing.T) {
+	mod, err := DecodeFile(filepath.Join("testdata", "save.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := Marshal(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := Decode(bytes.NewReader(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Marshal(again)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("encoding is not stable\nfirst: %s\nsecond: %s", first, second)
+	}
+}
+
+// TestDecodeLegacy decodes a save with a null EpochTime and a fractional
+// object Value, both must be kept as written.
+func TestDecodeLegacy(t *testing.T) {
+	mod, err := DecodeFile(filepath.Join("testdata", "legacy.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mod.EpochTime != nil {
+		t.Errorf("EpochTime = %d, want null", *mod.EpochTime)
+	}
+
+	if got := mod.Objects[0].Value; got != "2.5" {
+		t.Errorf("Value = %q, want 2.5", got)
+	}
+
+	encoded, err := Marshal(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Normalize(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{`"EpochTime":null`, `"Value":2.5`} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("encoded save lacks %s", want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package module
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+// positionEpsilon ignores physics jitter when comparing object positions.
+const positionEpsilon = 0.01
+
+// ObjectRef is an object of one side of a diff.
+type ObjectRef struct {
+	GUID     string
+	Name     string
+	Nickname string
+	Path     ObjectPath
+	// Location is the container of the object: the table, or the GUID of the
+	// parent and the field holding it, e.g. "a1b2c3/ContainedObjects".
+	Location string
+	Position Vector
+}
+
+// ObjectChange is an object present in both versions of the save.
+type ObjectChange struct {
+	Old, New ObjectRef
+
+	Moved              bool
+	NicknameChanged    bool
+	DescriptionChanged bool
+	ScriptChanged      bool
+
+	OldDescription, NewDescription string
+	OldScript, NewScript           string
+}
+
+// AssetChanges are asset URLs added or removed for one file type.
+type AssetChanges struct {
+	Type    service.FileType
+	Added   []string
+	Removed []string
+}
+
+type ModuleDiff struct {
+	Added   []ObjectRef
+	Removed []ObjectRef
+	Changed []ObjectChange
+
+	GlobalScriptChanged bool
+	OldGlobalScript     string
+	NewGlobalScript     string
+
+	Assets []AssetChanges
+}
+
+func (d *ModuleDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 &&
+		!d.GlobalScriptChanged && len(d.Assets) == 0
+}
+
+type diffObject struct {
+	ref         ObjectRef
+	description string
+	script      string
+}
+
+// Diff compares two versions of a save. Objects are matched by GUID, objects
+// sharing a GUID are matched in walking order. Objects without a GUID are ignored.
+func Diff(old, new *Module) *ModuleDiff {
+	d := &ModuleDiff{}
+
+	oldObjects := indexObjects(old)
+	newObjects := indexObjects(new)
+
+	for guid, olds := range oldObjects {
+		news := newObjects[guid]
+
+		for i, o := range olds {
+			if i >= len(news) {
+				d.Removed = append(d.Removed, o.ref)
+				continue
+			}
+
+			if change, ok := compareObjects(o, news[i]); ok {
+				d.Changed = append(d.Changed, change)
+			}
+		}
+	}
+
+	for guid, news := range newObjects {
+		for _, n := range news[min(len(oldObjects[guid]), len(news)):] {
+			d.Added = append(d.Added, n.ref)
+		}
+	}
+
+	byPath := func(a, b ObjectRef) int {
+		return cmp.Compare(a.Path.String(), b.Path.String())
+	}
+
+	slices.SortFunc(d.Added, byPath)
+	slices.SortFunc(d.Removed, byPath)
+	slices.SortFunc(d.Changed, func(a, b ObjectChange) int {
+		return byPath(a.New, b.New)
+	})
+
+	if old.LuaScript != new.LuaScript {
+		d.GlobalScriptChanged = true
+		d.OldGlobalScript = old.LuaScript
+		d.NewGlobalScript = new.LuaScript
+	}
+
+	d.Assets = diffAssets(old, new)
+
+	return d
+}
+
+func indexObjects(mod *Module) map[string][]diffObject {
+	objects := make(map[string][]diffObject)
+	guids := make(map[string]string)
+
+	mod.WalkObjects(func(path ObjectPath, obj *Object) {
+		guids[path.String()] = obj.GUID
+
+		if obj.GUID == "" {
+			return
+		}
+
+		location := "table"
+		if len(path) > 1 {
+			field, _, _ := strings.Cut(path[len(path)-1], "[")
+			location = guids[path[:len(path)-1].String()] + "/" + field
+		}
+
+		objects[obj.GUID] = append(objects[obj.GUID], diffObject{
+			ref: ObjectRef{
+				GUID:     obj.GUID,
+				Name:     obj.Name,
+				Nickname: obj.Nickname,
+				Path:     path,
+				Location: location,
+				Position: Vector{X: obj.Transform.PosX, Y: obj.Transform.PosY, Z: obj.Transform.PosZ},
+			},
+			description: obj.Description,
+			script:      obj.LuaScript,
+		})
+	})
+
+	return objects
+}
+
+func compareObjects(o, n diffObject) (ObjectChange, bool) {
+	change := ObjectChange{
+		Old: o.ref,
+		New: n.ref,
+
+		Moved: o.ref.Location != n.ref.Location ||
+			math.Abs(o.ref.Position.X-n.ref.Position.X) > positionEpsilon ||
+			math.Abs(o.ref.Position.Y-n.ref.Position.Y) > positionEpsilon ||
+			math.Abs(o.ref.Position.Z-n.ref.Position.Z) > positionEpsilon,
+		NicknameChanged:    o.ref.Nickname != n.ref.Nickname,
+		DescriptionChanged: o.description != n.description,
+		ScriptChanged:      o.script != n.script,
+	}
+
+	if change.DescriptionChanged {
+		change.OldDescription, change.NewDescription = o.description, n.description
+	}
+
+	if change.ScriptChanged {
+		change.OldScript, change.NewScript = o.script, n.script
+	}
+
+	ok := change.Moved || change.NicknameChanged || change.DescriptionChanged || change.ScriptChanged
+
+	return change, ok
+}
+
+func diffAssets(old, new *Module) []AssetChanges {
+	oldFiles := NewTTSModule()
+	oldFiles.ScanModule(old)
+
+	newFiles := NewTTSModule()
+	newFiles.ScanModule(new)
+
+	oldAll := oldFiles.GetAll()
+	newAll := newFiles.GetAll()
+
+	byType := make(map[service.FileType]*AssetChanges)
+	changes := func(t service.FileType) *AssetChanges {
+		if byType[t] == nil {
+			byType[t] = &AssetChanges{Type: t}
+		}
+
+		return byType[t]
+	}
+
+	for url, mf := range newAll {
+		if _, ok := oldAll[url]; !ok {
+			c := changes(mf.Type)
+			c.Added = append(c.Added, url)
+		}
+	}
+
+	for url, mf := range oldAll {
+		if _, ok := newAll[url]; !ok {
+			c := changes(mf.Type)
+			c.Removed = append(c.Removed, url)
+		}
+	}
+
+	result := make([]AssetChanges, 0, len(byType))
+	for _, c := range byType {
+		slices.Sort(c.Added)
+		slices.Sort(c.Removed)
+		result = append(result, *c)
+	}
+
+	slices.SortFunc(result, func(a, b AssetChanges) int {
+		return cmp.Compare(a.Type, b.Type)
+	})
+
+	return result
+}
+
+func (r ObjectRef) String() string {
+	name := r.Nickname
+	if name == "" {
+		name = r.Name
+	}
+
+	return fmt.Sprintf("%s %q (%s)", r.GUID, name, r.Path)
+}
@@ -1,81 +1,94 @@
 package module
 
+import "encoding/json"
+
 type Module struct {
-	SaveName      string `json:"SaveName"`
-	EpochTime     int    `json:"EpochTime"`
-	Date          string `json:"Date"`
-	VersionNumber string `json:"VersionNumber"`
-
-	// GameMode       string        `json:"GameMode"`
-	// GameType       string        `json:"GameType"`
-	// GameComplexity string        `json:"GameComplexity"`
-	// PlayingTime    []int         `json:"PlayingTime"`
-	// PlayerCounts   []int         `json:"PlayerCounts"`
-	// Tags           []string      `json:"Tags"`
-	// Gravity        float64       `json:"Gravity"`
-	// PlayArea       float64       `json:"PlayArea"`
-	// Table          string        `json:"Table"`
-	// Sky            string        `json:"Sky"`
-	// Note           string        `json:"Note"`
-	// TabStates      TabStates     `json:"TabStates"`
-	// Grid           Grid          `json:"Grid"`
-	// Lighting       Lighting      `json:"Lighting"`
-	// Hands          Hands         `json:"Hands"`
-	// ComponentTags  ComponentTags `json:"ComponentTags"`
-	// Turns          Turns         `json:"Turns"`
-	// DecalPallet    []any         `json:"DecalPallet"`
-	// LuaScript      string        `json:"LuaScript"`
-	// LuaScriptState string        `json:"LuaScriptState"`
-	// XMLUI          string        `json:"XmlUI"`
-
-	TableURL       string         `json:"TableURL"`
-	SkyURL         string         `json:"SkyURL"`
+	SaveName       string         `json:"SaveName"`
+	EpochTime      *int           `json:"EpochTime"`
+	Date           string         `json:"Date"`
+	VersionNumber  string         `json:"VersionNumber"`
+	GameMode       string         `json:"GameMode"`
+	GameType       string         `json:"GameType"`
+	GameComplexity string         `json:"GameComplexity"`
+	PlayingTime    []int          `json:"PlayingTime"`
+	PlayerCounts   []int          `json:"PlayerCounts"`
+	Tags           []string       `json:"Tags"`
+	Gravity        float64        `json:"Gravity"`
+	PlayArea       float64        `json:"PlayArea"`
+	Table          string         `json:"Table"`
+	TableURL       string         `json:"TableURL,omitempty"`
+	Sky            string         `json:"Sky"`
+	SkyURL         string         `json:"SkyURL,omitempty"`
+	Note           string         `json:"Note"`
+	TabStates      TabStates      `json:"TabStates"`
+	MusicPlayer    *MusicPlayer   `json:"MusicPlayer,omitempty"`
+	Grid           *Grid          `json:"Grid,omitempty"`
+	Lighting       *Lighting      `json:"Lighting,omitempty"`
+	Hands          *Hands         `json:"Hands,omitempty"`
+	ComponentTags  *ComponentTags `json:"ComponentTags,omitempty"`
+	Turns          *Turns         `json:"Turns,omitempty"`
+	CameraStates   []*CameraState `json:"CameraStates,omitempty"`
+	DecalPallet    []CustomDecal  `json:"DecalPallet,omitempty"`
+	LuaScript      string         `json:"LuaScript"`
+	LuaScriptState string         `json:"LuaScriptState"`
+	XMLUI          string         `json:"XmlUI"`
 	CustomUIAssets CustomUIAssets `json:"CustomUIAssets,omitempty"`
-	MusicPlayer    *MusicPlayer   `json:"MusicPlayer"`
+	SnapPoints     []SnapPoint    `json:"SnapPoints,omitempty"`
+	VectorLines    []VectorLine   `json:"VectorLines,omitempty"`
+	Decals         []Decal        `json:"Decals,omitempty"`
 
 	Objects []Object `json:"ObjectStates"`
+
+	Extra Overflow `json:"-"`
 }
 
 type Object struct {
-	// GUID                 string               `json:"GUID"`
-	// Name                 string               `json:"Name"`
-	// Transform            Transform            `json:"Transform"`
-	// Nickname             string               `json:"Nickname"`
-	// Description          string               `json:"Description"`
-	// GMNotes              string               `json:"GMNotes"`
-	// AltLookAngle         AltLookAngle         `json:"AltLookAngle"`
-	// ColorDiffuse         Color                `json:"ColorDiffuse,omitempty"`
-	// LayoutGroupSortIndex int                  `json:"LayoutGroupSortIndex"`
-	// Value                int                  `json:"Value"`
-	// Locked               bool                 `json:"Locked"`
-	// Grid                 bool                 `json:"Grid"`
-	// Snap                 bool                 `json:"Snap"`
-	// IgnoreFoW            bool                 `json:"IgnoreFoW"`
-	// MeasureMovement      bool                 `json:"MeasureMovement"`
-	// DragSelectable       bool                 `json:"DragSelectable"`
-	// Autoraise            bool                 `json:"Autoraise"`
-	// Sticky               bool                 `json:"Sticky"`
-	// Tooltip              bool                 `json:"Tooltip"`
-	// GridProjection       bool                 `json:"GridProjection"`
-	// HideWhenFaceDown     bool                 `json:"HideWhenFaceDown"`
-	// Hands                bool                 `json:"Hands"`
-	// FogColor             string               `json:"FogColor,omitempty"`
-	// LuaScript            string               `json:"LuaScript"`
-	// LuaScriptState       string               `json:"LuaScriptState"`
-	// XMLUI                string               `json:"XmlUI"`
-	// PhysicsMaterial      PhysicsMaterial      `json:"PhysicsMaterial,omitempty"`
-	// Rigidbody            Rigidbody            `json:"Rigidbody,omitempty"`
-	// MaterialIndex        int                  `json:"MaterialIndex,omitempty"`
-	// MeshIndex            int                  `json:"MeshIndex,omitempty"`
-	// Bag                  Bag                  `json:"Bag,omitempty"`
-	// RotationValues       []RotationValues     `json:"RotationValues,omitempty"`
-	// Clock                Clock                `json:"Clock,omitempty"`
-	// SidewaysCard         bool                 `json:"SidewaysCard,omitempty"`
-	// DeckIDs              []int                `json:"DeckIDs,omitempty"`
-	// CardID               int                  `json:"CardID,omitempty"`
-	// Number               int                  `json:"Number,omitempty"`
-	// Text                 Text                 `json:"Text,omitempty"`
-	// AttachedSnapPoints   []AttachedSnapPoints `json:"AttachedSnapPoints,omitempty"`
+	GUID                 string            `json:"GUID"`
+	Name                 string            `json:"Name"`
+	Transform            Transform         `json:"Transform"`
+	Nickname             string            `json:"Nickname"`
+	Description          string            `json:"Description"`
+	GMNotes              string            `json:"GMNotes"`
+	Memo                 string            `json:"Memo,omitempty"`
+	AltLookAngle         Vector            `json:"AltLookAngle"`
+	ColorDiffuse         Color             `json:"ColorDiffuse"`
+	Tags                 []string          `json:"Tags,omitempty"`
+	LayoutGroupSortIndex int               `json:"LayoutGroupSortIndex"`
+	Value                json.Number       `json:"Value"`
+	Locked               bool              `json:"Locked"`
+	Grid                 bool              `json:"Grid"`
+	Snap                 bool              `json:"Snap"`
+	IgnoreFoW            bool              `json:"IgnoreFoW"`
+	MeasureMovement      bool              `json:"MeasureMovement"`
+	DragSelectable       bool              `json:"DragSelectable"`
+	Autoraise            bool              `json:"Autoraise"`
+	Sticky               bool              `json:"Sticky"`
+	Tooltip              bool              `json:"Tooltip"`
+	GridProjection       bool              `json:"GridProjection"`
+	HideWhenFaceDown     bool              `json:"HideWhenFaceDown"`
+	Hands                bool              `json:"Hands"`
+	AltSound             bool              `json:"AltSound,omitempty"`
+	MaterialIndex        int               `json:"MaterialIndex,omitempty"`
+	MeshIndex            int               `json:"MeshIndex,omitempty"`
+	Number               int               `json:"Number,omitempty"`
+	CardID               int               `json:"CardID,omitempty"`
+	SidewaysCard         bool              `json:"SidewaysCard,omitempty"`
+	DeckIDs              []int             `json:"DeckIDs,omitempty"`
+	FogColor             string            `json:"FogColor,omitempty"`
+	RotationValues       []RotationValue   `json:"RotationValues,omitempty"`
+	Bag                  *Bag              `json:"Bag,omitempty"`
+	Clock                *Clock            `json:"Clock,omitempty"`
+	Counter              *Counter          `json:"Counter,omitempty"`
+	Text                 *Text             `json:"Text,omitempty"`
+	Tablet               *Tablet           `json:"Tablet,omitempty"`
+	FogOfWarRevealer     *FogOfWarRevealer `json:"FogOfWarRevealer,omitempty"`
+	PhysicsMaterial      *PhysicsMaterial  `json:"PhysicsMaterial,omitempty"`
+	Rigidbody            *Rigidbody        `json:"Rigidbody,omitempty"`
+	LuaScript            string            `json:"LuaScript"`
+	LuaScriptState       string            `json:"LuaScriptState"`
+	XMLUI                string            `json:"XmlUI"`
+	AttachedSnapPoints   []SnapPoint       `json:"AttachedSnapPoints,omitempty"`
+	AttachedVectorLines  []VectorLine      `json:"AttachedVectorLines,omitempty"`
 
 	CustomDeck        CustomDeck         `json:"CustomDeck,omitempty"`
 	AttachedDecals    AttachedDecals     `json:"AttachedDecals,omitempty"`
@@ -88,25 +101,40 @@ type Object struct {
 	States           States   `json:"States,omitempty"`
 	ContainedObjects []Object `json:"ContainedObjects,omitempty"`
 	ChildObjects     []Object `json:"ChildObjects,omitempty"`
+
+	Extra Overflow `json:"-"`
 }
 
-// type Transform struct {
-// 	PosX float64 `json:"posX"`
-// 	PosY float64 `json:"posY"`
-// 	PosZ float64 `json:"posZ"`
-// 	RotX float64 `json:"rotX"`
-// 	RotY float64 `json:"rotY"`
-// 	RotZ float64 `json:"rotZ"`
-// 	ScaleX float64 `json:"scaleX"`
-// 	ScaleY float64 `json:"scaleY"`
-// 	ScaleZ float64 `json:"scaleZ"`
-// }
-
-// type AltLookAngle struct {
-// 	X float64 `json:"x"`
-// 	Y float64 `json:"y"`
-// 	Z float64 `json:"z"`
-// }
+type Transform struct {
+	PosX   float64 `json:"posX"`
+	PosY   float64 `json:"posY"`
+	PosZ   float64 `json:"posZ"`
+	RotX   float64 `json:"rotX"`
+	RotY   float64 `json:"rotY"`
+	RotZ   float64 `json:"rotZ"`
+	ScaleX float64 `json:"scaleX"`
+	ScaleY float64 `json:"scaleY"`
+	ScaleZ float64 `json:"scaleZ"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Vector struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Color struct {
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+	A float64 `json:"a,omitempty"`
+
+	Extra Overflow `json:"-"`
+}
 
 type CustomUIAssets []CustomUIAsset
 
@@ -114,6 +142,8 @@ type CustomUIAsset struct {
 	Type int    `json:"Type"`
 	Name string `json:"Name"`
 	URL  string `json:"URL"`
+
+	Extra Overflow `json:"-"`
 }
 
 type CustomAssetbundle struct {
@@ -122,92 +152,150 @@ type CustomAssetbundle struct {
 	MaterialIndex           int    `json:"MaterialIndex"`
 	TypeIndex               int    `json:"TypeIndex"`
 	LoopingEffectIndex      int    `json:"LoopingEffectIndex"`
+
+	Extra Overflow `json:"-"`
+}
+
+type PhysicsMaterial struct {
+	StaticFriction  float64 `json:"StaticFriction"`
+	DynamicFriction float64 `json:"DynamicFriction"`
+	Bounciness      float64 `json:"Bounciness"`
+	FrictionCombine int     `json:"FrictionCombine"`
+	BounceCombine   int     `json:"BounceCombine"`
+
+	Extra Overflow `json:"-"`
 }
 
-// type PhysicsMaterial struct {
-// 	StaticFriction  float64 `json:"StaticFriction"`
-// 	DynamicFriction float64 `json:"DynamicFriction"`
-// 	Bounciness      float64 `json:"Bounciness"`
-// 	FrictionCombine int     `json:"FrictionCombine"`
-// 	BounceCombine   int     `json:"BounceCombine"`
-// }
+type Rigidbody struct {
+	Mass        float64 `json:"Mass"`
+	Drag        float64 `json:"Drag"`
+	AngularDrag float64 `json:"AngularDrag"`
+	UseGravity  bool    `json:"UseGravity"`
 
-// type Rigidbody struct {
-// 	Mass        float64 `json:"Mass"`
-// 	Drag        float64 `json:"Drag"`
-// 	AngularDrag float64 `json:"AngularDrag"`
-// 	UseGravity  bool    `json:"UseGravity"`
-// }
+	Extra Overflow `json:"-"`
+}
 
 type States map[string]Object
 
 type CustomMesh struct {
-	MeshURL     string `json:"MeshURL"`
-	DiffuseURL  string `json:"DiffuseURL"`
-	NormalURL   string `json:"NormalURL"`
-	ColliderURL string `json:"ColliderURL"`
-	// Convex        bool         `json:"Convex"`
-	// MaterialIndex int          `json:"MaterialIndex"`
-	// TypeIndex     int          `json:"TypeIndex"`
-	// CustomShader  CustomShader `json:"CustomShader"`
-	// CastShadows   bool         `json:"CastShadows"`
-}
-
-// type Bag struct {
-// 	Order int `json:"Order"`
-// }
-
-// type CustomShader struct {
-// 	SpecularColor     Color   `json:"SpecularColor"`
-// 	SpecularIntensity float64 `json:"SpecularIntensity"`
-// 	SpecularSharpness float64 `json:"SpecularSharpness"`
-// 	FresnelStrength   float64 `json:"FresnelStrength"`
-// }
+	MeshURL       string        `json:"MeshURL"`
+	DiffuseURL    string        `json:"DiffuseURL"`
+	NormalURL     string        `json:"NormalURL"`
+	ColliderURL   string        `json:"ColliderURL"`
+	Convex        bool          `json:"Convex"`
+	MaterialIndex int           `json:"MaterialIndex"`
+	TypeIndex     int           `json:"TypeIndex"`
+	CustomShader  *CustomShader `json:"CustomShader,omitempty"`
+	CastShadows   bool          `json:"CastShadows"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Bag struct {
+	Order int `json:"Order"`
+
+	Extra Overflow `json:"-"`
+}
+
+type CustomShader struct {
+	SpecularColor     Color   `json:"SpecularColor"`
+	SpecularIntensity float64 `json:"SpecularIntensity"`
+	SpecularSharpness float64 `json:"SpecularSharpness"`
+	FresnelStrength   float64 `json:"FresnelStrength"`
+
+	Extra Overflow `json:"-"`
+}
 
 type CustomImage struct {
-	ImageURL          string `json:"ImageURL"`
-	ImageSecondaryURL string `json:"ImageSecondaryURL"`
-	// ImageScalar       float64    `json:"ImageScalar"`
-	// WidthScale        float64    `json:"WidthScale"`
-	// CustomDice        CustomDice `json:"CustomDice"`
-}
-
-// type CustomDice struct {
-// 	Type int `json:"Type"`
-// }
-
-// type Rotation struct {
-// 	X float64 `json:"x"`
-// 	Y float64 `json:"y"`
-// 	Z float64 `json:"z"`
-// }
-
-// type RotationValues struct {
-// 	Value    string   `json:"Value"`
-// 	Rotation Rotation `json:"Rotation"`
-// }
-
-// type CustomTile struct {
-// 	Type      int     `json:"Type"`
-// 	Thickness float64 `json:"Thickness"`
-// 	Stackable bool    `json:"Stackable"`
-// 	Stretch   bool    `json:"Stretch"`
-// }
-
-// type Clock struct {
-// 	Mode          int  `json:"Mode"`
-// 	SecondsPassed int  `json:"SecondsPassed"`
-// 	Paused        bool `json:"Paused"`
-// }
+	ImageURL           string              `json:"ImageURL"`
+	ImageSecondaryURL  string              `json:"ImageSecondaryURL"`
+	ImageScalar        float64             `json:"ImageScalar"`
+	WidthScale         float64             `json:"WidthScale"`
+	CustomDice         *CustomDice         `json:"CustomDice,omitempty"`
+	CustomTile         *CustomTile         `json:"CustomTile,omitempty"`
+	CustomToken        *CustomToken        `json:"CustomToken,omitempty"`
+	CustomJigsawPuzzle *CustomJigsawPuzzle `json:"CustomJigsawPuzzle,omitempty"`
+
+	Extra Overflow `json:"-"`
+}
+
+type CustomDice struct {
+	Type int `json:"Type"`
+
+	Extra Overflow `json:"-"`
+}
+
+type CustomTile struct {
+	Type      int     `json:"Type"`
+	Thickness float64 `json:"Thickness"`
+	Stackable bool    `json:"Stackable"`
+	Stretch   bool    `json:"Stretch"`
+
+	Extra Overflow `json:"-"`
+}
+
+type CustomToken struct {
+	Thickness           float64 `json:"Thickness"`
+	MergeDistancePixels float64 `json:"MergeDistancePixels"`
+	StandUp             bool    `json:"StandUp"`
+	Stackable           bool    `json:"Stackable"`
+
+	Extra Overflow `json:"-"`
+}
+
+type CustomJigsawPuzzle struct {
+	NumPuzzlePieces int  `json:"NumPuzzlePieces"`
+	ImageOnBoard    bool `json:"ImageOnBoard"`
+
+	Extra Overflow `json:"-"`
+}
+
+type RotationValue struct {
+	// Value is a number for standard dice and a string for custom ones.
+	Value    any    `json:"Value"`
+	Rotation Vector `json:"Rotation"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Clock struct {
+	Mode          int  `json:"Mode"`
+	SecondsPassed int  `json:"SecondsPassed"`
+	Paused        bool `json:"Paused"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Counter struct {
+	Value int `json:"value"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Tablet struct {
+	PageURL string `json:"PageURL"`
+
+	Extra Overflow `json:"-"`
+}
+
+type FogOfWarRevealer struct {
+	Active bool    `json:"Active"`
+	Range  float64 `json:"Range"`
+	Color  string  `json:"Color"`
+
+	Extra Overflow `json:"-"`
+}
 
 type CustomCard struct {
-	FaceURL string `json:"FaceURL"`
-	BackURL string `json:"BackURL"`
-	// NumWidth     int    `json:"NumWidth"`
-	// NumHeight    int    `json:"NumHeight"`
-	// BackIsHidden bool   `json:"BackIsHidden"`
-	// UniqueBack   bool   `json:"UniqueBack"`
-	// Type         int    `json:"Type"`
+	FaceURL      string `json:"FaceURL"`
+	BackURL      string `json:"BackURL"`
+	NumWidth     int    `json:"NumWidth"`
+	NumHeight    int    `json:"NumHeight"`
+	BackIsHidden bool   `json:"BackIsHidden"`
+	UniqueBack   bool   `json:"UniqueBack"`
+	Type         int    `json:"Type"`
+
+	Extra Overflow `json:"-"`
 }
 
 type CustomDeck map[string]CustomCard
@@ -215,116 +303,160 @@ type CustomDeck map[string]CustomCard
 type AttachedDecals []Decal
 
 type Decal struct {
+	Transform   *Transform   `json:"Transform,omitempty"`
 	CustomDecal *CustomDecal `json:"CustomDecal,omitempty"`
+
+	Extra Overflow `json:"-"`
 }
 
 type CustomDecal struct {
-	Name     string `json:"Name"`
-	ImageURL string `json:"ImageURL"`
-	// Size     float64 `json:"Size"`
+	Name     string  `json:"Name"`
+	ImageURL string  `json:"ImageURL"`
+	Size     float64 `json:"Size"`
+
+	Extra Overflow `json:"-"`
 }
 
-// type Text struct {
-// 	Text       string `json:"Text"`
-// 	Colorstate Color  `json:"colorstate"`
-// 	FontSize   int    `json:"fontSize"`
-// }
+type Text struct {
+	Text       string `json:"Text"`
+	Colorstate Color  `json:"colorstate"`
+	FontSize   int    `json:"fontSize"`
 
-// type Position struct {
-// 	X float64 `json:"x"`
-// 	Y float64 `json:"y"`
-// 	Z float64 `json:"z"`
-// }
+	Extra Overflow `json:"-"`
+}
 
-// type AttachedSnapPoints struct {
-// 	Position Position `json:"Position"`
-// }
+type SnapPoint struct {
+	Position Vector   `json:"Position"`
+	Rotation *Vector  `json:"Rotation,omitempty"`
+	Tags     []string `json:"Tags,omitempty"`
+
+	Extra Overflow `json:"-"`
+}
+
+type VectorLine struct {
+	Points    []Vector `json:"points3"`
+	Color     Color    `json:"color"`
+	Thickness float64  `json:"thickness"`
+	Rotation  Vector   `json:"rotation"`
+	Loop      bool     `json:"loop,omitempty"`
+	Square    bool     `json:"square,omitempty"`
+
+	Extra Overflow `json:"-"`
+}
 
 type CustomPDF struct {
-	PDFURL string `json:"PDFUrl"`
-	// PDFPassword   string `json:"PDFPassword"`
-	// PDFPage       int    `json:"PDFPage"`
-	// PDFPageOffset int    `json:"PDFPageOffset"`
+	PDFURL        string `json:"PDFUrl"`
+	PDFPassword   string `json:"PDFPassword"`
+	PDFPage       int    `json:"PDFPage"`
+	PDFPageOffset int    `json:"PDFPageOffset"`
+
+	Extra Overflow `json:"-"`
 }
 
-// type TabState struct {
-// 	Title        string `json:"title"`
-// 	Body         string `json:"body"`
-// 	Color        string `json:"color"`
-// 	VisibleColor Color  `json:"visibleColor"`
-// 	ID           int    `json:"id"`
-// }
+type TabState struct {
+	Title        string `json:"title"`
+	Body         string `json:"body"`
+	Color        string `json:"color"`
+	VisibleColor Color  `json:"visibleColor"`
+	ID           int    `json:"id"`
+
+	Extra Overflow `json:"-"`
+}
 
-// type TabStates map[string]TabState
+type TabStates map[string]TabState
 
 type AudioLibrary map[string]string
 
 type MusicPlayer struct {
-	// RepeatSong        bool           `json:"RepeatSong"`
-	// PlaylistEntry     int            `json:"PlaylistEntry"`
-	// CurrentAudioTitle string         `json:"CurrentAudioTitle"`
-	// CurrentAudioURL   string         `json:"CurrentAudioURL"`
-	AudioLibrary []AudioLibrary `json:"AudioLibrary"`
-}
-
-// type Color struct {
-// 	R float64 `json:"r"`
-// 	G float64 `json:"g"`
-// 	B float64 `json:"b"`
-// 	A float64 `json:"a,omitempty"`
-// }
-
-type PosOffset struct {
-	// X float64 `json:"x"`
-	// Y float64 `json:"y"`
-	// Z float64 `json:"z"`
-}
-
-// type Grid struct {
-// 	Type         int       `json:"Type"`
-// 	Lines        bool      `json:"Lines"`
-// 	Color        Color     `json:"Color"`
-// 	Opacity      float64   `json:"Opacity"`
-// 	ThickLines   bool      `json:"ThickLines"`
-// 	Snapping     bool      `json:"Snapping"`
-// 	Offset       bool      `json:"Offset"`
-// 	BothSnapping bool      `json:"BothSnapping"`
-// 	XSize        float64   `json:"xSize"`
-// 	YSize        float64   `json:"ySize"`
-// 	PosOffset    PosOffset `json:"PosOffset"`
-// }
-
-// type Lighting struct {
-// 	LightIntensity      float64 `json:"LightIntensity"`
-// 	LightColor          Color   `json:"LightColor"`
-// 	AmbientIntensity    float64 `json:"AmbientIntensity"`
-// 	AmbientType         int     `json:"AmbientType"`
-// 	AmbientSkyColor     Color   `json:"AmbientSkyColor"`
-// 	AmbientEquatorColor Color   `json:"AmbientEquatorColor"`
-// 	AmbientGroundColor  Color   `json:"AmbientGroundColor"`
-// 	ReflectionIntensity float64 `json:"ReflectionIntensity"`
-// 	LutIndex            int     `json:"LutIndex"`
-// 	LutContribution     float64 `json:"LutContribution"`
-// 	LutURL              string  `json:"LutURL"`
-// }
-
-// type Hands struct {
-// 	Enable        bool `json:"Enable"`
-// 	DisableUnused bool `json:"DisableUnused"`
-// 	Hiding        int  `json:"Hiding"`
-// }
-
-// type ComponentTags struct {
-// 	Labels []any `json:"labels"`
-// }
-
-// type Turns struct {
-// 	Enable              bool   `json:"Enable"`
-// 	Type                int    `json:"Type"`
-// 	TurnOrder           []any  `json:"TurnOrder"`
-// 	Reverse             bool   `json:"Reverse"`
-// 	SkipEmpty           bool   `json:"SkipEmpty"`
-// 	DisableInteractions bool   `json:"DisableInteractions"`
-// 	PassTurns           bool   `json:"PassTurns"`
-// 	TurnColor           string `json:"TurnColor"`
-// }
+	RepeatSong        bool           `json:"RepeatSong"`
+	PlaylistEntry     int            `json:"PlaylistEntry"`
+	CurrentAudioTitle string         `json:"CurrentAudioTitle"`
+	CurrentAudioURL   string         `json:"CurrentAudioURL"`
+	AudioLibrary      []AudioLibrary `json:"AudioLibrary"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Grid struct {
+	Type         int     `json:"Type"`
+	Lines        bool    `json:"Lines"`
+	Color        Color   `json:"Color"`
+	Opacity      float64 `json:"Opacity"`
+	ThickLines   bool    `json:"ThickLines"`
+	Snapping     bool    `json:"Snapping"`
+	Offset       bool    `json:"Offset"`
+	BothSnapping bool    `json:"BothSnapping"`
+	XSize        float64 `json:"xSize"`
+	YSize        float64 `json:"ySize"`
+	PosOffset    Vector  `json:"PosOffset"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Lighting struct {
+	LightIntensity      float64 `json:"LightIntensity"`
+	LightColor          Color   `json:"LightColor"`
+	AmbientIntensity    float64 `json:"AmbientIntensity"`
+	AmbientType         int     `json:"AmbientType"`
+	AmbientSkyColor     Color   `json:"AmbientSkyColor"`
+	AmbientEquatorColor Color   `json:"AmbientEquatorColor"`
+	AmbientGroundColor  Color   `json:"AmbientGroundColor"`
+	ReflectionIntensity float64 `json:"ReflectionIntensity"`
+	LutIndex            int     `json:"LutIndex"`
+	LutContribution     float64 `json:"LutContribution"`
+	LutURL              string  `json:"LutURL,omitempty"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Hands struct {
+	Enable         bool            `json:"Enable"`
+	DisableUnused  bool            `json:"DisableUnused"`
+	Hiding         int             `json:"Hiding"`
+	HandTransforms []HandTransform `json:"HandTransforms,omitempty"`
+
+	Extra Overflow `json:"-"`
+}
+
+type HandTransform struct {
+	Color     string    `json:"Color"`
+	Transform Transform `json:"Transform"`
+
+	Extra Overflow `json:"-"`
+}
+
+type ComponentTags struct {
+	Labels []ComponentTag `json:"labels"`
+
+	Extra Overflow `json:"-"`
+}
+
+type ComponentTag struct {
+	Displayed  string `json:"displayed"`
+	Normalized string `json:"normalized"`
+
+	Extra Overflow `json:"-"`
+}
+
+type Turns struct {
+	Enable              bool     `json:"Enable"`
+	Type                int      `json:"Type"`
+	TurnOrder           []string `json:"TurnOrder"`
+	Reverse             bool     `json:"Reverse"`
+	SkipEmpty           bool     `json:"SkipEmpty"`
+	DisableInteractions bool     `json:"DisableInteractions"`
+	PassTurns           bool     `json:"PassTurns"`
+	TurnColor           string   `json:"TurnColor"`
+
+	Extra Overflow `json:"-"`
+}
+
+type CameraState struct {
+	Position         Vector  `json:"Position"`
+	Rotation         Vector  `json:"Rotation"`
+	Distance         float64 `json:"Distance"`
+	Zoomed           bool    `json:"Zoomed"`
+	AbsolutePosition *Vector `json:"AbsolutePosition,omitempty"`
+
+	Extra Overflow `json:"-"`
+}
@@ -0,0 +1,394 @@
+package module
+
+// Types carrying an Overflow decode through their plain counterpart so that key
+// order and unknown keys survive a decode/encode round-trip.
+
+func (m *Module) UnmarshalJSON(data []byte) error {
+	type plain Module
+	return unmarshalWithOverflow(data, (*plain)(m), &m.Extra)
+}
+
+func (m Module) MarshalJSON() ([]byte, error) {
+	type plain Module
+	return marshalWithOverflow((*plain)(&m), &m.Extra)
+}
+
+func (o *Object) UnmarshalJSON(data []byte) error {
+	type plain Object
+	return unmarshalWithOverflow(data, (*plain)(o), &o.Extra)
+}
+
+func (o Object) MarshalJSON() ([]byte, error) {
+	type plain Object
+	return marshalWithOverflow((*plain)(&o), &o.Extra)
+}
+
+func (c *Color) UnmarshalJSON(data []byte) error {
+	type plain Color
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c Color) MarshalJSON() ([]byte, error) {
+	type plain Color
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomUIAsset) UnmarshalJSON(data []byte) error {
+	type plain CustomUIAsset
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomUIAsset) MarshalJSON() ([]byte, error) {
+	type plain CustomUIAsset
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomAssetbundle) UnmarshalJSON(data []byte) error {
+	type plain CustomAssetbundle
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomAssetbundle) MarshalJSON() ([]byte, error) {
+	type plain CustomAssetbundle
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomMesh) UnmarshalJSON(data []byte) error {
+	type plain CustomMesh
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomMesh) MarshalJSON() ([]byte, error) {
+	type plain CustomMesh
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomImage) UnmarshalJSON(data []byte) error {
+	type plain CustomImage
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomImage) MarshalJSON() ([]byte, error) {
+	type plain CustomImage
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomCard) UnmarshalJSON(data []byte) error {
+	type plain CustomCard
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomCard) MarshalJSON() ([]byte, error) {
+	type plain CustomCard
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (d *Decal) UnmarshalJSON(data []byte) error {
+	type plain Decal
+	return unmarshalWithOverflow(data, (*plain)(d), &d.Extra)
+}
+
+func (d Decal) MarshalJSON() ([]byte, error) {
+	type plain Decal
+	return marshalWithOverflow((*plain)(&d), &d.Extra)
+}
+
+func (c *CustomDecal) UnmarshalJSON(data []byte) error {
+	type plain CustomDecal
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomDecal) MarshalJSON() ([]byte, error) {
+	type plain CustomDecal
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (s *SnapPoint) UnmarshalJSON(data []byte) error {
+	type plain SnapPoint
+	return unmarshalWithOverflow(data, (*plain)(s), &s.Extra)
+}
+
+func (s SnapPoint) MarshalJSON() ([]byte, error) {
+	type plain SnapPoint
+	return marshalWithOverflow((*plain)(&s), &s.Extra)
+}
+
+func (v *VectorLine) UnmarshalJSON(data []byte) error {
+	type plain VectorLine
+	return unmarshalWithOverflow(data, (*plain)(v), &v.Extra)
+}
+
+func (v VectorLine) MarshalJSON() ([]byte, error) {
+	type plain VectorLine
+	return marshalWithOverflow((*plain)(&v), &v.Extra)
+}
+
+func (c *CustomPDF) UnmarshalJSON(data []byte) error {
+	type plain CustomPDF
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomPDF) MarshalJSON() ([]byte, error) {
+	type plain CustomPDF
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (t *TabState) UnmarshalJSON(data []byte) error {
+	type plain TabState
+	return unmarshalWithOverflow(data, (*plain)(t), &t.Extra)
+}
+
+func (t TabState) MarshalJSON() ([]byte, error) {
+	type plain TabState
+	return marshalWithOverflow((*plain)(&t), &t.Extra)
+}
+
+func (m *MusicPlayer) UnmarshalJSON(data []byte) error {
+	type plain MusicPlayer
+	return unmarshalWithOverflow(data, (*plain)(m), &m.Extra)
+}
+
+func (m MusicPlayer) MarshalJSON() ([]byte, error) {
+	type plain MusicPlayer
+	return marshalWithOverflow((*plain)(&m), &m.Extra)
+}
+
+func (g *Grid) UnmarshalJSON(data []byte) error {
+	type plain Grid
+	return unmarshalWithOverflow(data, (*plain)(g), &g.Extra)
+}
+
+func (g Grid) MarshalJSON() ([]byte, error) {
+	type plain Grid
+	return marshalWithOverflow((*plain)(&g), &g.Extra)
+}
+
+func (l *Lighting) UnmarshalJSON(data []byte) error {
+	type plain Lighting
+	return unmarshalWithOverflow(data, (*plain)(l), &l.Extra)
+}
+
+func (l Lighting) MarshalJSON() ([]byte, error) {
+	type plain Lighting
+	return marshalWithOverflow((*plain)(&l), &l.Extra)
+}
+
+func (h *Hands) UnmarshalJSON(data []byte) error {
+	type plain Hands
+	return unmarshalWithOverflow(data, (*plain)(h), &h.Extra)
+}
+
+func (h Hands) MarshalJSON() ([]byte, error) {
+	type plain Hands
+	return marshalWithOverflow((*plain)(&h), &h.Extra)
+}
+
+func (t *Turns) UnmarshalJSON(data []byte) error {
+	type plain Turns
+	return unmarshalWithOverflow(data, (*plain)(t), &t.Extra)
+}
+
+func (t Turns) MarshalJSON() ([]byte, error) {
+	type plain Turns
+	return marshalWithOverflow((*plain)(&t), &t.Extra)
+}
+
+func (c *CameraState) UnmarshalJSON(data []byte) error {
+	type plain CameraState
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CameraState) MarshalJSON() ([]byte, error) {
+	type plain CameraState
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (t *Transform) UnmarshalJSON(data []byte) error {
+	type plain Transform
+	return unmarshalWithOverflow(data, (*plain)(t), &t.Extra)
+}
+
+func (t Transform) MarshalJSON() ([]byte, error) {
+	type plain Transform
+	return marshalWithOverflow((*plain)(&t), &t.Extra)
+}
+
+func (v *Vector) UnmarshalJSON(data []byte) error {
+	type plain Vector
+	return unmarshalWithOverflow(data, (*plain)(v), &v.Extra)
+}
+
+func (v Vector) MarshalJSON() ([]byte, error) {
+	type plain Vector
+	return marshalWithOverflow((*plain)(&v), &v.Extra)
+}
+
+func (p *PhysicsMaterial) UnmarshalJSON(data []byte) error {
+	type plain PhysicsMaterial
+	return unmarshalWithOverflow(data, (*plain)(p), &p.Extra)
+}
+
+func (p PhysicsMaterial) MarshalJSON() ([]byte, error) {
+	type plain PhysicsMaterial
+	return marshalWithOverflow((*plain)(&p), &p.Extra)
+}
+
+func (r *Rigidbody) UnmarshalJSON(data []byte) error {
+	type plain Rigidbody
+	return unmarshalWithOverflow(data, (*plain)(r), &r.Extra)
+}
+
+func (r Rigidbody) MarshalJSON() ([]byte, error) {
+	type plain Rigidbody
+	return marshalWithOverflow((*plain)(&r), &r.Extra)
+}
+
+func (b *Bag) UnmarshalJSON(data []byte) error {
+	type plain Bag
+	return unmarshalWithOverflow(data, (*plain)(b), &b.Extra)
+}
+
+func (b Bag) MarshalJSON() ([]byte, error) {
+	type plain Bag
+	return marshalWithOverflow((*plain)(&b), &b.Extra)
+}
+
+func (c *CustomShader) UnmarshalJSON(data []byte) error {
+	type plain CustomShader
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomShader) MarshalJSON() ([]byte, error) {
+	type plain CustomShader
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomDice) UnmarshalJSON(data []byte) error {
+	type plain CustomDice
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomDice) MarshalJSON() ([]byte, error) {
+	type plain CustomDice
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomTile) UnmarshalJSON(data []byte) error {
+	type plain CustomTile
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomTile) MarshalJSON() ([]byte, error) {
+	type plain CustomTile
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomToken) UnmarshalJSON(data []byte) error {
+	type plain CustomToken
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomToken) MarshalJSON() ([]byte, error) {
+	type plain CustomToken
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *CustomJigsawPuzzle) UnmarshalJSON(data []byte) error {
+	type plain CustomJigsawPuzzle
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c CustomJigsawPuzzle) MarshalJSON() ([]byte, error) {
+	type plain CustomJigsawPuzzle
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (r *RotationValue) UnmarshalJSON(data []byte) error {
+	type plain RotationValue
+	return unmarshalWithOverflow(data, (*plain)(r), &r.Extra)
+}
+
+func (r RotationValue) MarshalJSON() ([]byte, error) {
+	type plain RotationValue
+	return marshalWithOverflow((*plain)(&r), &r.Extra)
+}
+
+func (c *Clock) UnmarshalJSON(data []byte) error {
+	type plain Clock
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c Clock) MarshalJSON() ([]byte, error) {
+	type plain Clock
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *Counter) UnmarshalJSON(data []byte) error {
+	type plain Counter
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c Counter) MarshalJSON() ([]byte, error) {
+	type plain Counter
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (t *Tablet) UnmarshalJSON(data []byte) error {
+	type plain Tablet
+	return unmarshalWithOverflow(data, (*plain)(t), &t.Extra)
+}
+
+func (t Tablet) MarshalJSON() ([]byte, error) {
+	type plain Tablet
+	return marshalWithOverflow((*plain)(&t), &t.Extra)
+}
+
+func (f *FogOfWarRevealer) UnmarshalJSON(data []byte) error {
+	type plain FogOfWarRevealer
+	return unmarshalWithOverflow(data, (*plain)(f), &f.Extra)
+}
+
+func (f FogOfWarRevealer) MarshalJSON() ([]byte, error) {
+	type plain FogOfWarRevealer
+	return marshalWithOverflow((*plain)(&f), &f.Extra)
+}
+
+func (t *Text) UnmarshalJSON(data []byte) error {
+	type plain Text
+	return unmarshalWithOverflow(data, (*plain)(t), &t.Extra)
+}
+
+func (t Text) MarshalJSON() ([]byte, error) {
+	type plain Text
+	return marshalWithOverflow((*plain)(&t), &t.Extra)
+}
+
+func (h *HandTransform) UnmarshalJSON(data []byte) error {
+	type plain HandTransform
+	return unmarshalWithOverflow(data, (*plain)(h), &h.Extra)
+}
+
+func (h HandTransform) MarshalJSON() ([]byte, error) {
+	type plain HandTransform
+	return marshalWithOverflow((*plain)(&h), &h.Extra)
+}
+
+func (c *ComponentTags) UnmarshalJSON(data []byte) error {
+	type plain ComponentTags
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c ComponentTags) MarshalJSON() ([]byte, error) {
+	type plain ComponentTags
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
+
+func (c *ComponentTag) UnmarshalJSON(data []byte) error {
+	type plain ComponentTag
+	return unmarshalWithOverflow(data, (*plain)(c), &c.Extra)
+}
+
+func (c ComponentTag) MarshalJSON() ([]byte, error) {
+	type plain ComponentTag
+	return marshalWithOverflow((*plain)(&c), &c.Extra)
+}
@@ -2,7 +2,7 @@ package module
 
 import (
 	"maps"
-	"net/url"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -40,6 +40,11 @@ func (mf ModuleFile) GetExtension() string {
 	}
 }
 
+// GetRelativePath returns where the file lives inside a TTS-like mods directory.
+func (mf ModuleFile) GetRelativePath() string {
+	return filepath.Join(mf.GetFolder(), mf.GetFilename()) + mf.GetExtension()
+}
+
 func (mf ModuleFile) GetFolder() string {
 	switch mf.Type {
 	case service.FileTypeAsset:
@@ -78,7 +83,19 @@ type TTSModule struct {
 
 	Name          string
 	EpochTime     uint
+	SaveEpochTime int
 	VersionNumber *semver.Version
+
+	GameMode       string
+	GameType       string
+	GameComplexity string
+	MinPlayingTime int
+	MaxPlayingTime int
+	PlayerCounts   []int
+	Tags           []string
+	Table          string
+	Sky            string
+	Note           string
 }
 
 func (m *TTSModule) Merge(input *TTSModule) {
@@ -173,89 +190,6 @@ func (m *TTSModule) AddAudio(url string) {
 	m.Audio[url] = ModuleFile{URL: url, Type: service.FileTypeAudio}
 }
 
-func (m *TTSModule) AddAssetsBundle(b *CustomAssetbundle) {
-	if b == nil {
-		return
-	}
-
-	m.AddAsset(b.AssetbundleURL)
-}
-
-func (m *TTSModule) AddMesh(b *CustomMesh) {
-	if b == nil {
-		return
-	}
-
-	m.AddImage(b.DiffuseURL)
-	m.AddModel(b.MeshURL)
-	m.AddModel(b.ColliderURL)
-}
-
-func (m *TTSModule) AddUIAssets(b CustomUIAssets) {
-	if b == nil {
-		return
-	}
-
-	for _, asset := range b {
-		m.AddImage(asset.URL)
-	}
-}
-
-func (m *TTSModule) AddImages(b *CustomImage) {
-	if b == nil {
-		return
-	}
-
-	m.AddImage(b.ImageURL)
-
-	if b.ImageSecondaryURL != "" {
-		m.AddImage(b.ImageSecondaryURL)
-	}
-}
-
-func (m *TTSModule) AddPDFs(b *CustomPDF) {
-	if b == nil {
-		return
-	}
-
-	m.AddPDF(b.PDFURL)
-}
-
-func (m *TTSModule) AddDecals(b AttachedDecals) {
-	if b == nil {
-		return
-	}
-
-	for _, decal := range b {
-		if decal.CustomDecal == nil {
-			continue
-		}
-
-		m.AddImage(decal.CustomDecal.ImageURL)
-	}
-}
-
-func (m *TTSModule) AddDeck(b CustomDeck) {
-	if b == nil {
-		return
-	}
-
-	for _, card := range b {
-		m.AddImage(card.FaceURL)
-		m.AddImage(card.BackURL)
-	}
-}
-
-func (m *TTSModule) BatchAdd(state Object) {
-	m.AddAssetsBundle(state.CustomAssetbundle)
-	m.AddImages(state.CustomImage)
-	m.AddMesh(state.CustomMesh)
-	m.AddDeck(state.CustomDeck)
-	m.AddUIAssets(state.CustomUIAssets)
-	m.AddPDFs(state.CustomPDF)
-	m.AddDecals(state.AttachedDecals)
-}
-
 func (m *TTSModule) GetAll() FileMapping[ModuleFile] {
 	allLength := len(m.Assets) + len(m.Models) + len(m.Images) + len(m.PDFs) + len(m.Audio)
 
@@ -278,66 +212,82 @@ func (m *TTSModule) Contains(url string) bool {
 	return ok
 }
 
+func (m *TTSModule) Add(url string, t service.FileType) {
+	switch t {
+	case service.FileTypeAsset:
+		m.AddAsset(url)
+	case service.FileTypeModel:
+		m.AddModel(url)
+	case service.FileTypeImage:
+		m.AddImage(url)
+	case service.FileTypePDF:
+		m.AddPDF(url)
+	case service.FileTypeAudio:
+		m.AddAudio(url)
+	}
+}
+
 func (m *TTSModule) ScanModule(mod *Module) {
 	m.Name = mod.SaveName
+	if mod.EpochTime != nil {
+		m.SaveEpochTime = *mod.EpochTime
+	}
+
 	if mod.VersionNumber != "" {
 		m.VersionNumber = semver.MustParse(mod.VersionNumber)
 	} else {
 		m.VersionNumber = semver.MustParse("0")
 	}
 
-	if mod.TableURL != "" {
-		m.AddImage(mod.TableURL)
-	}
-
-	if mod.SkyURL != "" {
-		m.AddImage(mod.SkyURL)
-	}
-
-	if mod.MusicPlayer != nil {
-		if len(mod.MusicPlayer.AudioLibrary) > 0 {
-			for _, audio := range mod.MusicPlayer.AudioLibrary {
-				for _, val := range audio {
-					url, err := url.Parse(val)
-					if err == nil && (url.Scheme == "http" || url.Scheme == "https") {
-						m.AddAudio(val)
-					}
-				}
-			}
-		}
-	}
-
-	m.AddUIAssets(mod.CustomUIAssets)
-
-	for _, state := range mod.Objects {
-		m.Merge(scanObject(state))
-	}
+	m.GameMode = mod.GameMode
+	m.GameType = mod.GameType
+	m.GameComplexity = mod.GameComplexity
+	m.MinPlayingTime, m.MaxPlayingTime = playingTime(mod.PlayingTime)
+	m.PlayerCounts = playerCounts(mod.PlayerCounts)
+	m.Tags = mod.Tags
+	m.Table = mod.Table
+	m.Sky = mod.Sky
+	m.Note = mod.Note
+
+	mod.WalkURLs(func(u *string, t service.FileType) {
+		m.Add(*u, t)
+	})
 }
 
-func scanObject(state Object) *TTSModule {
-	result := NewTTSModule()
-
-	for _, state := range state.ContainedObjects {
-		result.BatchAdd(state)
-
-		result.Merge(scanObject(state))
+// playingTime returns the [min, max] minutes of the save, max is min when
+// the save has a single value.
+func playingTime(pt []int) (int, int) {
+	switch len(pt) {
+	case 0:
+		return 0, 0
+	case 1:
+		return pt[0], pt[0]
+	default:
+		return min(pt[0], pt[1]), max(pt[0], pt[1])
 	}
+}
 
-	for _, state := range state.States {
-		result.BatchAdd(state)
+// playerCounts expands the [min, max] range of the save into every count
+// supported. Other lists are taken as the supported counts.
+func playerCounts(pc []int) []int {
+	if len(pc) == 2 && pc[0] > 0 && pc[0] <= pc[1] {
+		counts := make([]int, 0, pc[1]-pc[0]+1)
+		for n := pc[0]; n <= pc[1]; n++ {
+			counts = append(counts, n)
+		}
 
-		result.Merge(scanObject(state))
+		return counts
 	}
 
-	for _, state := range state.ChildObjects {
-		result.BatchAdd(state)
+	counts := make([]int, 0, len(pc))
 
-		result.Merge(scanObject(state))
+	for _, n := range pc {
+		if n > 0 {
+			counts = append(counts, n)
+		}
 	}
 
-	result.BatchAdd(state)
-
-	return result
+	return counts
 }
 
 var urlStartRegex = regexp.MustCompile(`^http.*$`)
@@ -361,6 +311,18 @@ func FixURL(url string) string {
 	return strings.Replace(url, `http://cloud-3.steamusercontent.com`, `https://steamusercontent-a.akamaihd.net`, 1)
 }
 
+// CacheFilenames returns names TTS may have cached the file under: the name of
+// the URL and, for Steam files, the name of the legacy cloud-3 URL it was fixed from.
+func (mf ModuleFile) CacheFilenames() []string {
+	names := []string{mf.GetFilename()}
+
+	if legacy, ok := strings.CutPrefix(mf.URL, `https://steamusercontent-a.akamaihd.net`); ok {
+		names = append(names, FileNameFromURL(`http://cloud-3.steamusercontent.com`+legacy))
+	}
+
+	return names
+}
+
 var nonDigitRegex = regexp.MustCompile(`\W`)
 
 func FileNameFromURL(url string) string {
@@ -0,0 +1,271 @@
+package module
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Overflow keeps what the typed model does not cover: the original key order
+// of a JSON object and the raw values of keys without a matching field.
+// Types carrying it re-encode into the same key order they were decoded from,
+// which keeps round-trips lossless for fields the model does not know about yet.
+type Overflow struct {
+	decoded bool
+	order   []string
+	extra   map[string]json.RawMessage
+}
+
+// Keys returns unknown keys in their original order.
+func (o *Overflow) Keys() []string {
+	keys := make([]string, 0, len(o.extra))
+	for _, k := range o.order {
+		if _, ok := o.extra[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+func (o *Overflow) Get(key string) (json.RawMessage, bool) {
+	raw, ok := o.extra[key]
+	return raw, ok
+}
+
+func (o *Overflow) Set(key string, raw json.RawMessage) {
+	if o.extra == nil {
+		o.extra = make(map[string]json.RawMessage, 1)
+	}
+
+	if _, ok := o.extra[key]; !ok {
+		o.order = append(o.order, key)
+	}
+
+	o.extra[key] = raw
+}
+
+func (o *Overflow) Delete(key string) {
+	delete(o.extra, key)
+}
+
+type jsonField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+type jsonFields struct {
+	list   []jsonField
+	byName map[string]jsonField
+}
+
+var fieldsCache sync.Map
+
+func fieldsOf(t reflect.Type) *jsonFields {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.(*jsonFields)
+	}
+
+	fields := &jsonFields{
+		byName: make(map[string]jsonField, t.NumField()),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+
+		f := jsonField{
+			name:      name,
+			index:     i,
+			omitEmpty: strings.Contains(opts, "omitempty"),
+		}
+
+		fields.list = append(fields.list, f)
+		fields.byName[name] = f
+	}
+
+	fieldsCache.Store(t, fields)
+
+	return fields
+}
+
+// unmarshalWithOverflow decodes a JSON object into v (a pointer to a struct without
+// its own UnmarshalJSON) and stores key order and unknown keys into ov.
+func unmarshalWithOverflow(data []byte, v any, ov *Overflow) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok == nil {
+		return nil
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected object, got %v", tok)
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	fields := fieldsOf(rv.Type())
+
+	*ov = Overflow{decoded: true}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key := tok.(string)
+
+		var raw json.RawMessage
+		err = dec.Decode(&raw)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+
+		f, ok := fields.byName[key]
+		if !ok {
+			ov.Set(key, raw)
+			continue
+		}
+
+		ov.order = append(ov.order, key)
+
+		err = json.Unmarshal(raw, rv.Field(f.index).Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// marshalWithOverflow encodes v (a pointer to a struct without its own MarshalJSON)
+// keeping the original key order recorded in ov. Keys present in the source are
+// always written back, new fields follow in declaration order. For decoded values
+// fields absent in the source are written only once they are set.
+func marshalWithOverflow(v any, ov *Overflow) ([]byte, error) {
+	rv := reflect.ValueOf(v).Elem()
+	fields := fieldsOf(rv.Type())
+
+	buf := bytes.NewBuffer(make([]byte, 0, 256))
+	buf.WriteByte('{')
+
+	written := make(map[string]struct{}, len(ov.order))
+
+	writeKey := func(key string, raw []byte) error {
+		if len(written) > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := marshalNoEscape(key)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(raw)
+
+		written[key] = struct{}{}
+
+		return nil
+	}
+
+	writeField := func(f jsonField) error {
+		raw, err := marshalNoEscape(rv.Field(f.index).Interface())
+		if err != nil {
+			return fmt.Errorf("key %q: %w", f.name, err)
+		}
+
+		return writeKey(f.name, raw)
+	}
+
+	for _, key := range ov.order {
+		if _, ok := written[key]; ok {
+			continue
+		}
+
+		if f, ok := fields.byName[key]; ok {
+			if err := writeField(f); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if raw, ok := ov.extra[key]; ok {
+			if err := writeKey(key, raw); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, f := range fields.list {
+		if _, ok := written[f.name]; ok {
+			continue
+		}
+
+		if (f.omitEmpty || ov.decoded) && isEmptyValue(rv.Field(f.index)) {
+			continue
+		}
+
+		if err := writeField(f); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// marshalNoEscape works like json.Marshal but keeps <, > and & as is,
+// TTS scripts and XML UI are full of them.
+func marshalNoEscape(v any) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer, reflect.Struct:
+		return v.IsZero()
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package module
+
+import (
+	"cmp"
+	"slices"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+// FileChanges between files stored for a module and the freshly scanned module.
+type FileChanges struct {
+	// Added are available files the module references but which are not stored yet.
+	Added []service.File
+	// Removed are stored files the module no longer references.
+	Removed []service.File
+	// Unchanged are stored files the module still references.
+	Unchanged []service.File
+	// Updated are unchanged files whose size or ETag differs from the stored ones,
+	// e.g. after they were downloaded again.
+	Updated []service.File
+	// Missing are referenced files neither stored nor available, e.g. failed downloads.
+	Missing []ModuleFile
+}
+
+func (c FileChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Updated) == 0
+}
+
+// Reconcile compares the stored files of the module with what it references now.
+// available are the referenced files present in the store, only those are added.
+func (m *TTSModule) Reconcile(stored []service.File, available []service.File) FileChanges {
+	all := m.GetAll()
+
+	changes := FileChanges{}
+	storedURLs := make(map[string]struct{}, len(stored))
+
+	availableByURL := make(map[string]service.File, len(available))
+	for _, f := range available {
+		availableByURL[f.URL] = f
+	}
+
+	for _, f := range stored {
+		mf, ok := all[f.URL]
+		if !ok || mf.Type != f.Type {
+			changes.Removed = append(changes.Removed, f)
+			continue
+		}
+
+		storedURLs[f.URL] = struct{}{}
+		changes.Unchanged = append(changes.Unchanged, f)
+
+		if a, ok := availableByURL[f.URL]; ok && (a.Size != f.Size || a.ETag != f.ETag) {
+			f.Size = a.Size
+			f.ETag = a.ETag
+			changes.Updated = append(changes.Updated, f)
+		}
+	}
+
+	availableURLs := make(map[string]struct{}, len(available))
+
+	for _, f := range available {
+		if _, ok := availableURLs[f.URL]; ok {
+			continue
+		}
+
+		if mf, ok := all[f.URL]; !ok || mf.Type != f.Type {
+			continue
+		}
+
+		availableURLs[f.URL] = struct{}{}
+
+		if _, ok := storedURLs[f.URL]; ok {
+			continue
+		}
+
+		f.ModuleID = m.ID
+		changes.Added = append(changes.Added, f)
+	}
+
+	for url, mf := range all {
+		_, isStored := storedURLs[url]
+		_, isAvailable := availableURLs[url]
+
+		if !isStored && !isAvailable {
+			changes.Missing = append(changes.Missing, mf)
+		}
+	}
+
+	slices.SortFunc(changes.Missing, func(a, b ModuleFile) int {
+		return cmp.Compare(a.URL, b.URL)
+	})
+
+	return changes
+}
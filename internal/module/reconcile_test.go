@@ -0,0 +1,159 @@
+package module
+
+import (
+	"reflect"
+	"testing"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+func TestReconcile(t *testing.T) {
+	const (
+		image = "http://example.com/image.png"
+		model = "http://example.com/model.obj"
+		audio = "http://example.com/audio.mp3"
+	)
+
+	file := func(url string, typ service.FileType, size int64, etag string) service.File {
+		return service.File{ModuleID: 1, Type: typ, URL: url, Size: size, ETag: etag}
+	}
+
+	type want struct {
+		added, removed, unchanged, updated []service.File
+		missing                            []string
+	}
+
+	tests := []struct {
+		name       string
+		references map[string]service.FileType
+		stored     []service.File
+		available  []service.File
+		want       want
+	}{
+		{
+			name:       "nothing stored",
+			references: map[string]service.FileType{image: service.FileTypeImage, model: service.FileTypeModel},
+			available:  []service.File{file(image, service.FileTypeImage, 10, "")},
+			want: want{
+				added:   []service.File{file(image, service.FileTypeImage, 10, "")},
+				missing: []string{model},
+			},
+		},
+		{
+			name:       "available twice",
+			references: map[string]service.FileType{image: service.FileTypeImage},
+			available: []service.File{
+				file(image, service.FileTypeImage, 10, ""),
+				file(image, service.FileTypeImage, 10, ""),
+			},
+			want: want{
+				added: []service.File{file(image, service.FileTypeImage, 10, "")},
+			},
+		},
+		{
+			name:       "unchanged",
+			references: map[string]service.FileType{image: service.FileTypeImage},
+			stored:     []service.File{file(image, service.FileTypeImage, 10, `"a"`)},
+			available:  []service.File{file(image, service.FileTypeImage, 10, `"a"`)},
+			want: want{
+				unchanged: []service.File{file(image, service.FileTypeImage, 10, `"a"`)},
+			},
+		},
+		{
+			name:       "stored but not available",
+			references: map[string]service.FileType{image: service.FileTypeImage},
+			stored:     []service.File{file(image, service.FileTypeImage, 10, "")},
+			want: want{
+				unchanged: []service.File{file(image, service.FileTypeImage, 10, "")},
+			},
+		},
+		{
+			name:       "downloaded again",
+			references: map[string]service.FileType{image: service.FileTypeImage, audio: service.FileTypeAudio},
+			stored: []service.File{
+				file(image, service.FileTypeImage, 10, `"a"`),
+				file(audio, service.FileTypeAudio, 30, ""),
+			},
+			available: []service.File{
+				file(image, service.FileTypeImage, 10, `"b"`),
+				file(audio, service.FileTypeAudio, 31, ""),
+			},
+			want: want{
+				unchanged: []service.File{
+					file(image, service.FileTypeImage, 10, `"a"`),
+					file(audio, service.FileTypeAudio, 30, ""),
+				},
+				updated: []service.File{
+					file(image, service.FileTypeImage, 10, `"b"`),
+					file(audio, service.FileTypeAudio, 31, ""),
+				},
+			},
+		},
+		{
+			name:       "no longer referenced",
+			references: map[string]service.FileType{},
+			stored:     []service.File{file(image, service.FileTypeImage, 10, "")},
+			available:  []service.File{file(image, service.FileTypeImage, 10, "")},
+			want: want{
+				removed: []service.File{file(image, service.FileTypeImage, 10, "")},
+			},
+		},
+		{
+			name:       "type changed",
+			references: map[string]service.FileType{model: service.FileTypeAsset},
+			stored:     []service.File{file(model, service.FileTypeModel, 20, "")},
+			available: []service.File{
+				file(model, service.FileTypeModel, 20, ""),
+				file(model, service.FileTypeAsset, 20, ""),
+			},
+			want: want{
+				added:   []service.File{file(model, service.FileTypeAsset, 20, "")},
+				removed: []service.File{file(model, service.FileTypeModel, 20, "")},
+			},
+		},
+		{
+			name:       "missing",
+			references: map[string]service.FileType{image: service.FileTypeImage, audio: service.FileTypeAudio, model: service.FileTypeModel},
+			want: want{
+				missing: []string{audio, image, model},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewTTSModule()
+			m.ID = 1
+
+			for url, typ := range tt.references {
+				m.Add(url, typ)
+			}
+
+			changes := m.Reconcile(tt.stored, tt.available)
+
+			check := func(kind string, got, want any) {
+				t.Helper()
+
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: got %+v, want %+v", kind, got, want)
+				}
+			}
+
+			var missing []string
+			for _, mf := range changes.Missing {
+				missing = append(missing, mf.URL)
+			}
+
+			check("added", changes.Added, tt.want.added)
+			check("removed", changes.Removed, tt.want.removed)
+			check("unchanged", changes.Unchanged, tt.want.unchanged)
+			check("updated", changes.Updated, tt.want.updated)
+			check("missing", missing, tt.want.missing)
+
+			wantEmpty := len(tt.want.added) == 0 && len(tt.want.removed) == 0 && len(tt.want.updated) == 0
+			if changes.IsEmpty() != wantEmpty {
+				t.Errorf("IsEmpty() = %v, want %v", changes.IsEmpty(), wantEmpty)
+			}
+		})
+	}
+}
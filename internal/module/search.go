@@ -0,0 +1,73 @@
+package module
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+// SearchDocuments returns the text of the save worth searching: the module
+// name with its note and tags, nicknames and descriptions of objects and
+// notebook tabs. Objects without text are skipped.
+func (mod *Module) SearchDocuments(id uint) []service.SearchDocument {
+	docs := make([]service.SearchDocument, 0)
+
+	docs = append(docs, service.SearchDocument{
+		ModuleID: id,
+		Kind:     service.SearchDocumentModule,
+		Title:    mod.SaveName,
+		Body:     joinText(mod.GameType, mod.GameMode, strings.Join(mod.Tags, ", "), mod.Note),
+	})
+
+	mod.WalkObjects(func(path ObjectPath, obj *Object) {
+		if strings.TrimSpace(obj.Nickname) == "" && strings.TrimSpace(obj.Description) == "" {
+			return
+		}
+
+		docs = append(docs, service.SearchDocument{
+			ModuleID: id,
+			Kind:     service.SearchDocumentObject,
+			GUID:     obj.GUID,
+			Path:     path.String(),
+			Title:    obj.Nickname,
+			Body:     obj.Description,
+		})
+	})
+
+	// tabs are keyed by their position, keep the notebook order
+	keys := slices.SortedFunc(maps.Keys(mod.TabStates), func(a, b string) int {
+		return cmp.Compare(mod.TabStates[a].ID, mod.TabStates[b].ID)
+	})
+
+	for _, key := range keys {
+		tab := mod.TabStates[key]
+		if strings.TrimSpace(tab.Body) == "" {
+			continue
+		}
+
+		docs = append(docs, service.SearchDocument{
+			ModuleID: id,
+			Kind:     service.SearchDocumentNotebook,
+			Path:     "TabStates[" + key + "]",
+			Title:    tab.Title,
+			Body:     tab.Body,
+		})
+	}
+
+	return docs
+}
+
+func joinText(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+
+	return strings.Join(nonEmpty, "\n")
+}
@@ -0,0 +1,373 @@
+package module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Layout of an unpacked save:
+//
+//	save.json                 module without objects, global script and UI
+//	script.lua                global script
+//	ui.xml                    global XML UI
+//	objects/0000_<name>_<guid>/
+//	    object.json           object without nested objects, script and UI
+//	    script.lua
+//	    ui.xml
+//	    contained/...         contained objects, same layout
+//	    states/<key>_<name>_<guid>/...
+//	    children/...
+//
+// Folders are prefixed with their index so that packing restores the original order.
+const (
+	treeSaveFile      = "save.json"
+	treeObjectFile    = "object.json"
+	treeScriptFile    = "script.lua"
+	treeUIFile        = "ui.xml"
+	treeObjectsDir    = "objects"
+	treeContainedDir  = "contained"
+	treeStatesDir     = "states"
+	treeChildrenDir   = "children"
+	treeIndexMinWidth = 4
+)
+
+var ErrTreeIsNotEmpty = errors.New("directory is not empty")
+
+// Unpack explodes the save into an editable directory tree. Unless force is set
+// dir must be empty; with force only the files produced by Unpack are replaced,
+// anything else in dir (e.g. .git) is kept.
+func Unpack(mod *Module, dir string, force bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read dir: %w", err)
+	}
+
+	if len(entries) > 0 {
+		if !force {
+			return fmt.Errorf("%s: %w", dir, ErrTreeIsNotEmpty)
+		}
+
+		for _, name := range []string{treeSaveFile, treeScriptFile, treeUIFile, treeObjectsDir} {
+			err = os.RemoveAll(filepath.Join(dir, name))
+			if err != nil {
+				return fmt.Errorf("cleaning %s: %w", name, err)
+			}
+		}
+	}
+
+	err = os.MkdirAll(dir, 0o777)
+	if err != nil {
+		return fmt.Errorf("creating directories: %w", err)
+	}
+
+	root := *mod
+
+	root.LuaScript, err = writeTreeText(dir, treeScriptFile, root.LuaScript)
+	if err != nil {
+		return err
+	}
+
+	root.XMLUI, err = writeTreeText(dir, treeUIFile, root.XMLUI)
+	if err != nil {
+		return err
+	}
+
+	if len(root.Objects) > 0 {
+		err = unpackObjects(filepath.Join(dir, treeObjectsDir), root.Objects)
+		if err != nil {
+			return err
+		}
+
+		root.Objects = nil
+	}
+
+	return writeTreeJSON(filepath.Join(dir, treeSaveFile), &root)
+}
+
+func unpackObjects(dir string, objs []Object) error {
+	width := max(len(strconv.Itoa(len(objs)-1)), treeIndexMinWidth)
+
+	for i, obj := range objs {
+		name := fmt.Sprintf("%0*d_%s", width, i, treeObjectName(&obj))
+
+		err := unpackObject(filepath.Join(dir, name), obj)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func unpackObject(dir string, obj Object) error {
+	err := os.MkdirAll(dir, 0o777)
+	if err != nil {
+		return fmt.Errorf("creating directories: %w", err)
+	}
+
+	obj.LuaScript, err = writeTreeText(dir, treeScriptFile, obj.LuaScript)
+	if err != nil {
+		return err
+	}
+
+	obj.XMLUI, err = writeTreeText(dir, treeUIFile, obj.XMLUI)
+	if err != nil {
+		return err
+	}
+
+	if len(obj.ContainedObjects) > 0 {
+		err = unpackObjects(filepath.Join(dir, treeContainedDir), obj.ContainedObjects)
+		if err != nil {
+			return err
+		}
+
+		obj.ContainedObjects = nil
+	}
+
+	if len(obj.ChildObjects) > 0 {
+		err = unpackObjects(filepath.Join(dir, treeChildrenDir), obj.ChildObjects)
+		if err != nil {
+			return err
+		}
+
+		obj.ChildObjects = nil
+	}
+
+	if len(obj.States) > 0 {
+		for _, key := range slices.Sorted(maps.Keys(obj.States)) {
+			state := obj.States[key]
+			name := key + "_" + treeObjectName(&state)
+
+			err = unpackObject(filepath.Join(dir, treeStatesDir, name), state)
+			if err != nil {
+				return err
+			}
+		}
+
+		obj.States = nil
+	}
+
+	return writeTreeJSON(filepath.Join(dir, treeObjectFile), &obj)
+}
+
+// Pack rebuilds a save from a tree produced by Unpack.
+func Pack(dir string) (*Module, error) {
+	mod := new(Module)
+
+	err := readTreeJSON(filepath.Join(dir, treeSaveFile), mod)
+	if err != nil {
+		return nil, err
+	}
+
+	err = readTreeText(dir, treeScriptFile, &mod.LuaScript)
+	if err != nil {
+		return nil, err
+	}
+
+	err = readTreeText(dir, treeUIFile, &mod.XMLUI)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := packObjects(filepath.Join(dir, treeObjectsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	if objs != nil {
+		mod.Objects = objs
+	}
+
+	return mod, nil
+}
+
+// packObjects returns nil when dir does not exist.
+func packObjects(dir string) ([]Object, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	objs := make([]Object, 0, len(entries))
+
+	// ReadDir returns entries sorted by name, the index prefix keeps the order
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		obj, err := packObject(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		objs = append(objs, *obj)
+	}
+
+	return objs, nil
+}
+
+func packObject(dir string) (*Object, error) {
+	obj := new(Object)
+
+	err := readTreeJSON(filepath.Join(dir, treeObjectFile), obj)
+	if err != nil {
+		return nil, err
+	}
+
+	err = readTreeText(dir, treeScriptFile, &obj.LuaScript)
+	if err != nil {
+		return nil, err
+	}
+
+	err = readTreeText(dir, treeUIFile, &obj.XMLUI)
+	if err != nil {
+		return nil, err
+	}
+
+	contained, err := packObjects(filepath.Join(dir, treeContainedDir))
+	if err != nil {
+		return nil, err
+	}
+
+	if contained != nil {
+		obj.ContainedObjects = contained
+	}
+
+	children, err := packObjects(filepath.Join(dir, treeChildrenDir))
+	if err != nil {
+		return nil, err
+	}
+
+	if children != nil {
+		obj.ChildObjects = children
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, treeStatesDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		key, _, _ := strings.Cut(entry.Name(), "_")
+
+		state, err := packObject(filepath.Join(dir, treeStatesDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if obj.States == nil {
+			obj.States = make(States, len(entries))
+		}
+
+		obj.States[key] = *state
+	}
+
+	return obj, nil
+}
+
+var treeNameRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+const treeNameMaxLength = 40
+
+func treeObjectName(obj *Object) string {
+	name := obj.Nickname
+	if name == "" {
+		name = obj.Name
+	}
+
+	name = strings.Trim(treeNameRegex.ReplaceAllString(name, "-"), "-")
+	if runes := []rune(name); len(runes) > treeNameMaxLength {
+		name = strings.Trim(string(runes[:treeNameMaxLength]), "-")
+	}
+
+	guid := treeNameRegex.ReplaceAllString(obj.GUID, "")
+
+	switch {
+	case name == "":
+		return guid
+	case guid == "":
+		return name
+	default:
+		return name + "_" + guid
+	}
+}
+
+// writeTreeText stores non-empty text into dir/name and returns what should be left
+// in the JSON document instead.
+func writeTreeText(dir, name, text string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o666)
+	if err != nil {
+		return "", fmt.Errorf("writing %s: %w", name, err)
+	}
+
+	return "", nil
+}
+
+func readTreeText(dir, name string, text *string) error {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	*text = string(data)
+
+	return nil
+}
+
+func writeTreeJSON(path string, v any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	err = enc.Encode(v)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func readTreeJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package module
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestTreeRoundTrip unpacks the saves of testdata and packs them again, the
+// packed save must encode to the same bytes as the decoded one.
+func TestTreeRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			mod, err := DecodeFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := Marshal(mod)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir := t.TempDir()
+
+			err = Unpack(mod, dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			packed, err := Pack(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Marshal(packed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("pack changed the save\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+// TestUnpackNotEmpty refuses to unpack into a used directory unless forced.
+func TestUnpackNotEmpty(t *testing.T) {
+	mod, err := DecodeFile(filepath.Join("testdata", "save.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+
+	err = Unpack(mod, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Unpack(mod, dir, false)
+	if !errors.Is(err, ErrTreeIsNotEmpty) {
+		t.Errorf("got %v, want %v", err, ErrTreeIsNotEmpty)
+	}
+
+	err = Unpack(mod, dir, true)
+	if err != nil {
+		t.Errorf("forced unpack: %v", err)
+	}
+}
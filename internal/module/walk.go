@@ -0,0 +1,204 @@
+package module
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+// ObjectPath locates an object inside the save, e.g.
+// ObjectStates[3]/ContainedObjects[0]/States[2].
+type ObjectPath []string
+
+func (p ObjectPath) String() string {
+	return strings.Join(p, "/")
+}
+
+func (p ObjectPath) child(field string, key any) ObjectPath {
+	return append(slices.Clip(p), fmt.Sprintf("%s[%v]", field, key))
+}
+
+// WalkObjects calls fn for every object in the save, parents before their
+// contained objects, states and children. Objects are passed by pointer so fn
+// may modify them in place.
+func (mod *Module) WalkObjects(fn func(path ObjectPath, obj *Object)) {
+	for i := range mod.Objects {
+		walkObject(ObjectPath(nil).child("ObjectStates", i), &mod.Objects[i], fn)
+	}
+}
+
+func walkObject(path ObjectPath, obj *Object, fn func(path ObjectPath, obj *Object)) {
+	fn(path, obj)
+
+	for i := range obj.ContainedObjects {
+		walkObject(path.child("ContainedObjects", i), &obj.ContainedObjects[i], fn)
+	}
+
+	// map values are not addressable, walk a copy and store it back
+	for _, key := range slices.Sorted(maps.Keys(obj.States)) {
+		state := obj.States[key]
+		walkObject(path.child("States", key), &state, fn)
+		obj.States[key] = state
+	}
+
+	for i := range obj.ChildObjects {
+		walkObject(path.child("ChildObjects", i), &obj.ChildObjects[i], fn)
+	}
+}
+
+// WalkURLs calls fn for every non-empty asset URL of the save together with the
+// type it is cached as. fn may replace the URL through the pointer.
+func (mod *Module) WalkURLs(fn func(u *string, t service.FileType)) {
+	visit := func(u *string, t service.FileType) {
+		if *u == "" {
+			return
+		}
+
+		fn(u, t)
+	}
+
+	visit(&mod.TableURL, service.FileTypeImage)
+	visit(&mod.SkyURL, service.FileTypeImage)
+
+	if mod.Lighting != nil {
+		visit(&mod.Lighting.LutURL, service.FileTypeImage)
+	}
+
+	if mod.MusicPlayer != nil {
+		visit(&mod.MusicPlayer.CurrentAudioURL, service.FileTypeAudio)
+
+		for _, audio := range mod.MusicPlayer.AudioLibrary {
+			for key, val := range audio {
+				u, err := url.Parse(val)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+					continue
+				}
+
+				visit(&val, service.FileTypeAudio)
+				audio[key] = val
+			}
+		}
+	}
+
+	for i := range mod.DecalPallet {
+		visit(&mod.DecalPallet[i].ImageURL, service.FileTypeImage)
+	}
+
+	walkDecals(mod.Decals, visit)
+	walkUIAssets(mod.CustomUIAssets, visit)
+
+	mod.WalkObjects(func(_ ObjectPath, obj *Object) {
+		walkObjectURLs(obj, visit)
+	})
+}
+
+// FileSource is an object of the save referencing an asset.
+type FileSource struct {
+	Path     ObjectPath
+	GUID     string
+	Nickname string
+}
+
+// FileSources returns the objects referencing each asset URL. URLs used by the
+// save itself, e.g. the table, sky or music player, have no objects.
+func (mod *Module) FileSources() map[string][]FileSource {
+	sources := make(map[string][]FileSource)
+
+	mod.WalkObjects(func(path ObjectPath, obj *Object) {
+		seen := make(map[string]struct{})
+
+		walkObjectURLs(obj, func(u *string, _ service.FileType) {
+			if *u == "" {
+				return
+			}
+
+			if _, ok := seen[*u]; ok {
+				return
+			}
+
+			seen[*u] = struct{}{}
+			sources[*u] = append(sources[*u], FileSource{Path: path, GUID: obj.GUID, Nickname: obj.Nickname})
+		})
+	})
+
+	return sources
+}
+
+func walkObjectURLs(obj *Object, visit func(u *string, t service.FileType)) {
+	if obj.CustomAssetbundle != nil {
+		visit(&obj.CustomAssetbundle.AssetbundleURL, service.FileTypeAsset)
+		visit(&obj.CustomAssetbundle.AssetbundleSecondaryURL, service.FileTypeAsset)
+	}
+
+	if obj.CustomImage != nil {
+		visit(&obj.CustomImage.ImageURL, service.FileTypeImage)
+		visit(&obj.CustomImage.ImageSecondaryURL, service.FileTypeImage)
+	}
+
+	if obj.CustomMesh != nil {
+		visit(&obj.CustomMesh.DiffuseURL, service.FileTypeImage)
+		visit(&obj.CustomMesh.NormalURL, service.FileTypeImage)
+		visit(&obj.CustomMesh.MeshURL, service.FileTypeModel)
+		visit(&obj.CustomMesh.ColliderURL, service.FileTypeModel)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(obj.CustomDeck)) {
+		card := obj.CustomDeck[key]
+		visit(&card.FaceURL, service.FileTypeImage)
+		visit(&card.BackURL, service.FileTypeImage)
+		obj.CustomDeck[key] = card
+	}
+
+	walkUIAssets(obj.CustomUIAssets, visit)
+
+	if obj.CustomPDF != nil {
+		visit(&obj.CustomPDF.PDFURL, service.FileTypePDF)
+	}
+
+	walkDecals(obj.AttachedDecals, visit)
+}
+
+func walkDecals(decals []Decal, visit func(u *string, t service.FileType)) {
+	for i := range decals {
+		if decals[i].CustomDecal == nil {
+			continue
+		}
+
+		visit(&decals[i].CustomDecal.ImageURL, service.FileTypeImage)
+	}
+}
+
+// walkUIAssets visits custom UI assets, type 0 is an image and type 1 an
+// asset bundle.
+func walkUIAssets(assets CustomUIAssets, visit func(u *string, t service.FileType)) {
+	for i := range assets {
+		var t service.FileType = service.FileTypeImage
+		if assets[i].Type == 1 {
+			t = service.FileTypeAsset
+		}
+
+		visit(&assets[i].URL, t)
+	}
+}
+
+// RewriteURLs replaces every asset URL for which fn returns ok and reports
+// how many were replaced.
+func (mod *Module) RewriteURLs(fn func(u string, t service.FileType) (string, bool)) int {
+	count := 0
+
+	mod.WalkURLs(func(u *string, t service.FileType) {
+		replacement, ok := fn(*u, t)
+		if !ok || replacement == *u {
+			return
+		}
+
+		*u = replacement
+		count++
+	})
+
+	return count
+}
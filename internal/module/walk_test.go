@@ -0,0 +1,138 @@
+package module
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+// TestWalkURLs sets every URL field of a save to a distinct URL, each must be
+// visited once with the type it is cached as.
+func TestWalkURLs(t *testing.T) {
+	mod := &Module{
+		TableURL: "http://example.com/table.png",
+		SkyURL:   "http://example.com/sky.png",
+		Lighting: &Lighting{LutURL: "http://example.com/lut.png"},
+		MusicPlayer: &MusicPlayer{
+			CurrentAudioURL: "http://example.com/current.mp3",
+			AudioLibrary: []AudioLibrary{
+				{"Item1": "http://example.com/library.mp3", "Item2": "Library"},
+			},
+		},
+		DecalPallet: []CustomDecal{{Name: "Pallet", ImageURL: "http://example.com/pallet.png"}},
+		Decals:      []Decal{{CustomDecal: &CustomDecal{ImageURL: "http://example.com/decal.png"}}},
+		CustomUIAssets: CustomUIAssets{
+			{Type: 0, Name: "image", URL: "http://example.com/ui.png"},
+			{Type: 1, Name: "bundle", URL: "http://example.com/ui.unity3d"},
+		},
+		Objects: []Object{
+			{
+				CustomAssetbundle: &CustomAssetbundle{
+					AssetbundleURL:          "http://example.com/bundle.unity3d",
+					AssetbundleSecondaryURL: "http://example.com/secondary.unity3d",
+				},
+				CustomImage: &CustomImage{
+					ImageURL:          "http://example.com/image.png",
+					ImageSecondaryURL: "http://example.com/image-secondary.png",
+				},
+				CustomMesh: &CustomMesh{
+					MeshURL:     "http://example.com/mesh.obj",
+					DiffuseURL:  "http://example.com/diffuse.png",
+					NormalURL:   "http://example.com/normal.png",
+					ColliderURL: "http://example.com/collider.obj",
+				},
+				CustomDeck: CustomDeck{
+					"1": {FaceURL: "http://example.com/face.png", BackURL: "http://example.com/back.png"},
+				},
+				CustomUIAssets: CustomUIAssets{
+					{Type: 1, Name: "object bundle", URL: "http://example.com/object-ui.unity3d"},
+				},
+				CustomPDF:      &CustomPDF{PDFURL: "http://example.com/rules.pdf"},
+				AttachedDecals: AttachedDecals{{CustomDecal: &CustomDecal{ImageURL: "http://example.com/attached.png"}}},
+				ContainedObjects: []Object{
+					{CustomImage: &CustomImage{ImageURL: "http://example.com/contained.png"}},
+				},
+			},
+		},
+	}
+
+	want := map[string]service.FileType{
+		"http://example.com/table.png":           service.FileTypeImage,
+		"http://example.com/sky.png":             service.FileTypeImage,
+		"http://example.com/lut.png":             service.FileTypeImage,
+		"http://example.com/current.mp3":         service.FileTypeAudio,
+		"http://example.com/library.mp3":         service.FileTypeAudio,
+		"http://example.com/pallet.png":          service.FileTypeImage,
+		"http://example.com/decal.png":           service.FileTypeImage,
+		"http://example.com/ui.png":              service.FileTypeImage,
+		"http://example.com/ui.unity3d":          service.FileTypeAsset,
+		"http://example.com/bundle.unity3d":      service.FileTypeAsset,
+		"http://example.com/secondary.unity3d":   service.FileTypeAsset,
+		"http://example.com/image.png":           service.FileTypeImage,
+		"http://example.com/image-secondary.png": service.FileTypeImage,
+		"http://example.com/mesh.obj":            service.FileTypeModel,
+		"http://example.com/diffuse.png":         service.FileTypeImage,
+		"http://example.com/normal.png":          service.FileTypeImage,
+		"http://example.com/collider.obj":        service.FileTypeModel,
+		"http://example.com/face.png":            service.FileTypeImage,
+		"http://example.com/back.png":            service.FileTypeImage,
+		"http://example.com/object-ui.unity3d":   service.FileTypeAsset,
+		"http://example.com/rules.pdf":           service.FileTypePDF,
+		"http://example.com/attached.png":        service.FileTypeImage,
+		"http://example.com/contained.png":       service.FileTypeImage,
+	}
+
+	got := make(map[string]service.FileType)
+
+	mod.WalkURLs(func(u *string, typ service.FileType) {
+		if _, ok := got[*u]; ok {
+			t.Errorf("%s visited twice", *u)
+		}
+
+		got[*u] = typ
+	})
+
+	if !maps.Equal(got, want) {
+		for u, typ := range want {
+			if got[u] != typ {
+				t.Errorf("%s: got type %d, want %d", u, got[u], typ)
+			}
+		}
+
+		for u := range got {
+			if _, ok := want[u]; !ok {
+				t.Errorf("%s visited, not expected", u)
+			}
+		}
+	}
+}
+
+// TestRewriteURLs replaces every URL, the save must reference only the
+// replacements afterwards.
+func TestRewriteURLs(t *testing.T) {
+	mod := &Module{
+		Lighting:    &Lighting{LutURL: "http://example.com/lut.png"},
+		MusicPlayer: &MusicPlayer{CurrentAudioURL: "http://example.com/current.mp3"},
+		DecalPallet: []CustomDecal{{ImageURL: "http://example.com/pallet.png"}},
+		Decals:      []Decal{{CustomDecal: &CustomDecal{ImageURL: "http://example.com/decal.png"}}},
+		Objects: []Object{
+			{CustomMesh: &CustomMesh{NormalURL: "http://example.com/normal.png"}},
+		},
+	}
+
+	count := mod.RewriteURLs(func(u string, _ service.FileType) (string, bool) {
+		return strings.Replace(u, "http://example.com/", "file:///local/", 1), true
+	})
+
+	if count != 5 {
+		t.Errorf("rewrote %d URLs, want 5", count)
+	}
+
+	mod.WalkURLs(func(u *string, _ service.FileType) {
+		if !strings.HasPrefix(*u, "file:///local/") {
+			t.Errorf("%s not rewritten", *u)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRunIsNotFound = errors.New("run is not found")
+
+type RunStatus string
+
+const (
+	RunStatusRunning RunStatus = "running"
+	// RunStatusCompleted runs processed every selected module, some may have failed.
+	RunStatusCompleted RunStatus = "completed"
+	// RunStatusInterrupted runs were cancelled before processing every module.
+	RunStatusInterrupted RunStatus = "interrupted"
+)
+
+// Run is a download of the workshop directory.
+type Run struct {
+	ID uint
+
+	Command string
+	// Modules are the selected module IDs, every workshop file when empty.
+	Modules []uint
+	Status  RunStatus
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	// Parsed and Skipped count workshop files, Failed counts modules which
+	// could not be parsed or synced.
+	Parsed  int
+	Skipped int
+	Failed  int
+
+	FilesDownloaded int
+	FilesExisting   int
+	FilesFailed     int
+	// Bytes are downloaded by the run.
+	Bytes int64
+}
+
+func (r *Run) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return 0
+	}
+
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// RunModule is the result of a module processed by a run.
+type RunModule struct {
+	ID uint
+
+	RunID    uint
+	ModuleID uint
+	Name     string
+
+	FilesDownloaded int
+	FilesExisting   int
+	FilesFailed     int
+	FilesAdded      int
+	FilesRemoved    int
+	Bytes           int64
+	Duration        time.Duration
+	// Error is set when the module could not be parsed or synced.
+	Error string
+}
@@ -0,0 +1,37 @@
+package internal
+
+type Script struct {
+	ID uint
+
+	Hash    string
+	Size    int
+	Content string
+}
+
+// ScriptUsage is an object, or the global script, of a module using a script.
+type ScriptUsage struct {
+	ID uint
+
+	ScriptID uint
+	ModuleID uint
+	GUID     string
+	Nickname string
+	Path     string
+}
+
+// ScriptInclude links a luabundle script to the modules it was built from.
+type ScriptInclude struct {
+	ID uint
+
+	BundleID uint
+	Name     string
+	ScriptID uint
+}
+
+// ScriptMatch is a usage of a script found by a content search.
+type ScriptMatch struct {
+	ScriptUsage
+
+	Hash string
+	Size int
+}
@@ -0,0 +1,84 @@
+package script
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BundleModule is a file originally pulled in with #include / require and
+// inlined by luabundle (used by the Atom and VS Code TTS plugins).
+type BundleModule struct {
+	Name    string
+	Content string
+}
+
+var (
+	bundleRegisterRegex = regexp.MustCompile(`^\s*__bundle_register\s*\(\s*"((?:[^"\\]|\\.)*)"\s*,\s*function\s*\(\s*require\s*,\s*_LOADED\s*,\s*__bundle_register\s*,\s*__bundle_modules\s*\)\s*$`)
+	bundleReturnRegex   = regexp.MustCompile(`^\s*return\s+__bundle_require\s*\(`)
+)
+
+// IsBundle reports whether the script was generated by luabundle.
+func IsBundle(src string) bool {
+	return strings.Contains(src, "__bundle_register")
+}
+
+// Unbundle splits a luabundle-generated script into its modules in bundle order,
+// "__root" being the main script. It returns nil for scripts which are not bundles.
+func Unbundle(src string) []BundleModule {
+	if !IsBundle(src) {
+		return nil
+	}
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	var (
+		modules []BundleModule
+		current *BundleModule
+		body    []string
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+
+		// drop the "end)" closing the register call
+		for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+			body = body[:len(body)-1]
+		}
+
+		if len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "end)" {
+			body = body[:len(body)-1]
+		}
+
+		current.Content = strings.Join(body, "\n")
+		modules = append(modules, *current)
+
+		current = nil
+		body = nil
+	}
+
+	for _, line := range lines {
+		if subs := bundleRegisterRegex.FindStringSubmatch(line); subs != nil {
+			flush()
+
+			current = &BundleModule{Name: subs[1]}
+
+			continue
+		}
+
+		if bundleReturnRegex.MatchString(line) {
+			flush()
+
+			continue
+		}
+
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+
+	flush()
+
+	return modules
+}
@@ -0,0 +1,68 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnbundle(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "bundle.lua"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := string(data)
+
+	want := []BundleModule{
+		{Name: "__root", Content: "local util = require(\"lib/util\")\n\nfunction onLoad()\n  util.log(\"loaded\")\nend"},
+		{Name: "lib/util", Content: "local util = {}\n\nfunction util.log(message)\n  print(\"[util] \" .. message)\nend\n\nreturn util"},
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want []BundleModule
+	}{
+		{
+			name: "luabundle",
+			src:  bundle,
+			want: want,
+		},
+		{
+			name: "CRLF line endings",
+			src:  strings.ReplaceAll(bundle, "\n", "\r\n"),
+			want: want,
+		},
+		{
+			name: "spacing",
+			src: "__bundle_register( \"__root\" , function( require, _LOADED, __bundle_register, __bundle_modules ) \n" +
+				"print(\"root\")\n" +
+				"end)\n" +
+				"\t__bundle_register(\"a\\\"b\", function(require,_LOADED,__bundle_register,__bundle_modules)\n" +
+				"print(\"quoted\")\n" +
+				"end)\n" +
+				"return  __bundle_require (\"__root\")\n",
+			want: []BundleModule{
+				{Name: "__root", Content: "print(\"root\")"},
+				{Name: "a\\\"b", Content: "print(\"quoted\")"},
+			},
+		},
+		{
+			name: "not a bundle",
+			src:  "function onLoad()\nend\n",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unbundle(tt.src)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package script
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Usage is an object (or the global script) using a script.
+type Usage struct {
+	GUID     string
+	Nickname string
+	Path     string
+}
+
+type Include struct {
+	Name string
+	Hash string
+}
+
+type Entry struct {
+	Hash    string
+	Content string
+
+	Usages   []Usage
+	Includes []Include
+}
+
+// Catalog groups identical scripts by content hash. Bundles are unpacked and
+// their modules added as separate entries.
+type Catalog struct {
+	Entries map[string]*Entry
+	order   []string
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{
+		Entries: make(map[string]*Entry),
+	}
+}
+
+func Hash(src string) string {
+	sum := sha256.Sum256([]byte(src))
+	return hex.EncodeToString(sum[:])
+}
+
+// Add registers the script used by usage and returns its entry.
+func (c *Catalog) Add(src string, usage Usage) *Entry {
+	entry := c.entry(src)
+	entry.Usages = append(entry.Usages, usage)
+
+	return entry
+}
+
+func (c *Catalog) entry(src string) *Entry {
+	hash := Hash(src)
+
+	entry, ok := c.Entries[hash]
+	if ok {
+		return entry
+	}
+
+	entry = &Entry{
+		Hash:    hash,
+		Content: src,
+	}
+
+	c.Entries[hash] = entry
+	c.order = append(c.order, hash)
+
+	for _, m := range Unbundle(src) {
+		included := c.entry(m.Content)
+		entry.Includes = append(entry.Includes, Include{
+			Name: m.Name,
+			Hash: included.Hash,
+		})
+	}
+
+	return entry
+}
+
+// Merge adds entries and usages of other into the catalog.
+func (c *Catalog) Merge(other *Catalog) {
+	for _, e := range other.List() {
+		entry := c.entry(e.Content)
+		entry.Usages = append(entry.Usages, e.Usages...)
+	}
+}
+
+// List returns entries in the order they were first seen.
+func (c *Catalog) List() []*Entry {
+	entries := make([]*Entry, 0, len(c.order))
+	for _, hash := range c.order {
+		entries = append(entries, c.Entries[hash])
+	}
+
+	return entries
+}
@@ -0,0 +1,209 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines kept around each hunk.
+const diffContext = 3
+
+// maxDiffCells bounds the LCS table, larger scripts are diffed as a whole replacement.
+const maxDiffCells = 16 << 20
+
+type lineOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff returns the unified diff of two scripts, or an empty string when
+// they are equal.
+func UnifiedDiff(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+
+		if start == len(ops) {
+			break
+		}
+
+		from := max(start-diffContext, 0)
+
+		// extend the hunk while changes are closer than twice the context
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+
+			if next == len(ops) || next-end > 2*diffContext {
+				break
+			}
+
+			end = next
+		}
+
+		to := min(end+diffContext, len(ops))
+
+		writeHunk(sb, ops, from, to)
+
+		start = to
+	}
+
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []lineOp, from, to int) {
+	oldStart, newStart := 1, 1
+	for _, op := range ops[:from] {
+		if op.kind != '+' {
+			oldStart++
+		}
+
+		if op.kind != '-' {
+			newStart++
+		}
+	}
+
+	oldLen, newLen := 0, 0
+	for _, op := range ops[from:to] {
+		if op.kind != '+' {
+			oldLen++
+		}
+
+		if op.kind != '-' {
+			newLen++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLen), hunkRange(newStart, newLen))
+
+	for _, op := range ops[from:to] {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.line)
+		sb.WriteByte('\n')
+	}
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes an edit script through the longest common subsequence of
+// the lines, after trimming the common prefix and suffix.
+func diffLines(a, b []string) []lineOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]lineOp, 0, len(a)+len(b))
+
+	for _, line := range a[:prefix] {
+		ops = append(ops, lineOp{' ', line})
+	}
+
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, lineOp{' ', line})
+	}
+
+	return ops
+}
+
+func diffMiddle(a, b []string) []lineOp {
+	ops := make([]lineOp, 0, len(a)+len(b))
+
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, lineOp{'-', line})
+		}
+
+		for _, line := range b {
+			ops = append(ops, lineOp{'+', line})
+		}
+
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	width := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*width)
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, lineOp{' ', a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			ops = append(ops, lineOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, lineOp{'+', b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		ops = append(ops, lineOp{'-', a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		ops = append(ops, lineOp{'+', b[j]})
+	}
+
+	return ops
+}
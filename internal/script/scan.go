@@ -0,0 +1,163 @@
+package script
+
+import (
+	"regexp"
+	"strings"
+)
+
+type Kind string
+
+const (
+	// KindSignature matches known payloads, scripts can be cleaned automatically.
+	KindSignature Kind = "signature"
+	// KindHeuristic matches suspicious behaviour, scripts should be reviewed by hand.
+	KindHeuristic Kind = "heuristic"
+)
+
+type Rule struct {
+	Name        string
+	Kind        Kind
+	Description string
+
+	match func(src string) bool
+}
+
+type Finding struct {
+	Rule        string
+	Kind        Kind
+	Description string
+}
+
+// payloadMarkers start the self-replicating "tcejbo gninwapS" payload. The payload
+// copies itself from the first marker through the last one into every spawned
+// object.
+var payloadMarkers = []string{
+	"tcejbo gninwapS",
+	"--[[Object base code]]",
+}
+
+var (
+	setScriptRegex    = regexp.MustCompile(`\.\s*setLuaScript\s*\(`)
+	getScriptRegex    = regexp.MustCompile(`\.\s*getLuaScript\s*\(|script_code`)
+	spreadRegex       = regexp.MustCompile(`\bonObjectSpawn\b|\bgetAllObjects\s*\(|\bgetObjects\s*\(`)
+	webRequestRegex   = regexp.MustCompile(`\bWebRequest\s*\.\s*(get|post|put|custom)\b`)
+	dynamicLoadRegex  = regexp.MustCompile(`\b(load|loadstring)\s*\(`)
+	reversedCodeRegex = regexp.MustCompile(`:reverse\s*\(\s*\)|string\.reverse\s*\(`)
+)
+
+var Rules = []Rule{
+	{
+		Name:        "spawning-object-payload",
+		Kind:        KindSignature,
+		Description: `self-replicating "tcejbo gninwapS" payload`,
+		match: func(src string) bool {
+			return payloadStart(src) >= 0
+		},
+	},
+	{
+		Name:        "self-replication",
+		Kind:        KindHeuristic,
+		Description: "copies its own script into other objects",
+		match: func(src string) bool {
+			return setScriptRegex.MatchString(src) && getScriptRegex.MatchString(src) && spreadRegex.MatchString(src)
+		},
+	},
+	{
+		Name:        "remote-code",
+		Kind:        KindHeuristic,
+		Description: "downloads and executes code at runtime",
+		match: func(src string) bool {
+			return webRequestRegex.MatchString(src) && dynamicLoadRegex.MatchString(src)
+		},
+	},
+	{
+		Name:        "obfuscated-loader",
+		Kind:        KindHeuristic,
+		Description: "executes reversed or assembled code",
+		match: func(src string) bool {
+			return reversedCodeRegex.MatchString(src) && dynamicLoadRegex.MatchString(src)
+		},
+	},
+}
+
+// Scan applies every rule to the script.
+func Scan(src string) []Finding {
+	if src == "" {
+		return nil
+	}
+
+	var findings []Finding
+
+	for _, rule := range Rules {
+		if rule.match(src) {
+			findings = append(findings, Finding{
+				Rule:        rule.Name,
+				Kind:        rule.Kind,
+				Description: rule.Description,
+			})
+		}
+	}
+
+	return findings
+}
+
+// Clean strips the known payload: the lines from the first marker through the
+// last one, the payload delimits the copy it spreads with its markers. A payload
+// holding a single marker runs to the end of the script, which is where it
+// appends itself to its victims. Code around the payload is kept.
+// Scripts matched only by heuristics are returned unchanged.
+func Clean(src string) (string, bool) {
+	start := payloadStart(src)
+	if start < 0 {
+		return src, false
+	}
+
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	end := payloadEnd(src, start)
+
+	before := strings.TrimRight(src[:lineStart], " \t\r\n")
+	after := strings.TrimLeft(src[end:], "\r\n")
+
+	if before == "" || after == "" {
+		return before + after, true
+	}
+
+	return before + "\n\n" + after, true
+}
+
+// payloadEnd returns the end of the line holding the last marker, or the end of
+// the script when the payload has no closing marker.
+func payloadEnd(src string, start int) int {
+	last := start
+
+	for _, marker := range payloadMarkers {
+		idx := strings.LastIndex(src, marker)
+		if idx > last {
+			last = idx
+		}
+	}
+
+	if last == start {
+		return len(src)
+	}
+
+	nl := strings.IndexByte(src[last:], '\n')
+	if nl < 0 {
+		return len(src)
+	}
+
+	return last + nl + 1
+}
+
+func payloadStart(src string) int {
+	start := -1
+
+	for _, marker := range payloadMarkers {
+		idx := strings.Index(src, marker)
+		if idx >= 0 && (start < 0 || idx < start) {
+			start = idx
+		}
+	}
+
+	return start
+}
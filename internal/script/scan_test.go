@@ -0,0 +1,58 @@
+package script
+
+import "testing"
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		cleaned bool
+	}{
+		{
+			name:    "no payload",
+			src:     "function onLoad()\nend\n",
+			want:    "function onLoad()\nend\n",
+			cleaned: false,
+		},
+		{
+			name:    "payload at the end",
+			src:     "function onLoad()\nend\n\n--[[Object base code]]\nfunction onObjectSpawn(o)\n  o.setLuaScript(self.getLuaScript())\nend\n",
+			want:    "function onLoad()\nend",
+			cleaned: true,
+		},
+		{
+			name: "code after the payload",
+			src: "function onLoad()\n  print(\"loaded\")\nend\n\n" +
+				"--[[Object base code]]\n" +
+				"function onObjectSpawn(o)\n" +
+				"  local s = self.getLuaScript()\n" +
+				"  o.setLuaScript(s:sub(s:find(\"tcejbo gninwapS\")))\n" +
+				"end\n" +
+				"-- tcejbo gninwapS\n" +
+				"\n" +
+				"function onPlayerTurn(player)\n  print(player.color)\nend\n",
+			want:    "function onLoad()\n  print(\"loaded\")\nend\n\nfunction onPlayerTurn(player)\n  print(player.color)\nend\n",
+			cleaned: true,
+		},
+		{
+			name:    "script is only the payload",
+			src:     "--[[Object base code]]\nfunction onObjectSpawn(o) end\n-- tcejbo gninwapS\n",
+			want:    "",
+			cleaned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cleaned := Clean(tt.src)
+			if cleaned != tt.cleaned {
+				t.Errorf("cleaned = %v, want %v", cleaned, tt.cleaned)
+			}
+
+			if got != tt.want {
+				t.Errorf("Clean() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
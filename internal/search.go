@@ -0,0 +1,30 @@
+package internal
+
+type SearchDocumentKind string
+
+const (
+	SearchDocumentModule   SearchDocumentKind = "module"
+	SearchDocumentObject   SearchDocumentKind = "object"
+	SearchDocumentNotebook SearchDocumentKind = "notebook"
+)
+
+// SearchDocument is a piece of text of a module indexed for search: the
+// module itself, one of its objects or a notebook tab.
+type SearchDocument struct {
+	ID uint
+
+	ModuleID uint
+	Kind     SearchDocumentKind
+	// GUID and Path locate objects in the save.
+	GUID  string
+	Path  string
+	Title string
+	Body  string
+}
+
+// SearchHit is a document matching a query, a higher Rank is a better match.
+type SearchHit struct {
+	SearchDocument
+
+	Rank float64
+}
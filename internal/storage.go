@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+type ModuleRepository interface {
+	// Get returns ErrModuleIsNotFound for an unknown id.
+	Get(ctx context.Context, id uint) (*Module, error)
+	List(ctx context.Context) ([]Module, error)
+	ListByStatus(ctx context.Context, status ModuleStatus) ([]Module, error)
+	Find(ctx context.Context, filter ModuleFilter) ([]Module, error)
+	// Create returns ErrModuleConflict when the module exists.
+	Create(ctx context.Context, module *Module) error
+	// Upsert creates the module or replaces every field of the existing one,
+	// tags and player counts included.
+	Upsert(ctx context.Context, module *Module) error
+	// Update sets non-zero fields of the existing module, tags and player
+	// counts are left as they are.
+	Update(ctx context.Context, module *Module) error
+	// MarkSeen makes the modules active and sets the time their workshop file was last seen.
+	MarkSeen(ctx context.Context, at time.Time, ids ...uint) error
+	// MarkRemoved sets the removed status on active modules among ids.
+	MarkRemoved(ctx context.Context, at time.Time, ids ...uint) error
+	// UpdateStatus returns ErrModuleIsNotFound for an unknown id.
+	UpdateStatus(ctx context.Context, id uint, status ModuleStatus) error
+}
+
+type FileRepository interface {
+	List(ctx context.Context) ([]File, error)
+	ListByModuleID(ctx context.Context, id uint) ([]File, error)
+	// BatchCreate fails with ErrFileConflict when the module has stored a URL already.
+	BatchCreate(ctx context.Context, files ...File) error
+	DeleteByIDs(ctx context.Context, ids ...uint) error
+	DeleteByModuleID(ctx context.Context, id uint) error
+	// UpdateContents stores Size and ETag of the files found by their ID.
+	UpdateContents(ctx context.Context, files ...File) error
+	// Stats sums up files per module, modules without files are left out.
+	Stats(ctx context.Context) ([]ModuleFileStats, error)
+}
+
+type FileFailureRepository interface {
+	// ReplaceModule drops failures of the module and stores the given ones.
+	ReplaceModule(ctx context.Context, moduleID uint, failures ...FileFailure) error
+	List(ctx context.Context) ([]FileFailure, error)
+}
+
+type ModuleVersionRepository interface {
+	// Get returns the version including its data or ErrModuleVersionIsNotFound.
+	Get(ctx context.Context, id uint) (*ModuleVersion, error)
+	// ListByModuleID returns versions of the module oldest first, without data.
+	ListByModuleID(ctx context.Context, moduleID uint) ([]ModuleVersion, error)
+	// Create stores the version unless the module already has one with the same hash.
+	Create(ctx context.Context, version *ModuleVersion) error
+}
+
+type ScriptRepository interface {
+	// Upsert stores scripts missing by hash and fills in IDs of all of them.
+	Upsert(ctx context.Context, scripts ...*Script) error
+	BatchCreateIncludes(ctx context.Context, includes ...ScriptInclude) error
+	// ReplaceUsages drops usages of the module and stores the given ones.
+	ReplaceUsages(ctx context.Context, moduleID uint, usages ...ScriptUsage) error
+	// Search returns usages of scripts whose content contains text, including
+	// scripts reached through bundles only.
+	Search(ctx context.Context, text string) ([]ScriptMatch, error)
+}
+
+type RunRepository interface {
+	// Create stores the run and sets its ID.
+	Create(ctx context.Context, run *Run) error
+	// Update stores every field of the run, returns ErrRunIsNotFound for an unknown id.
+	Update(ctx context.Context, run *Run) error
+	// Get returns ErrRunIsNotFound for an unknown id.
+	Get(ctx context.Context, id uint) (*Run, error)
+	// List returns up to limit runs, newest first.
+	List(ctx context.Context, limit int) ([]Run, error)
+	AddModules(ctx context.Context, modules ...RunModule) error
+	// ListModules returns results of the run in the order they were added.
+	ListModules(ctx context.Context, runID uint) ([]RunModule, error)
+}
+
+type SearchRepository interface {
+	// ReplaceModule drops documents of the module and indexes the given ones.
+	ReplaceModule(ctx context.Context, moduleID uint, docs ...SearchDocument) error
+	// Search returns up to limit documents containing every word of query, best first.
+	Search(ctx context.Context, query string, limit int) ([]SearchHit, error)
+}
+
+// Storage gives access to the repositories of one database.
+type Storage interface {
+	Modules() ModuleRepository
+	Files() FileRepository
+	FileFailures() FileFailureRepository
+	ModuleVersions() ModuleVersionRepository
+	Scripts() ScriptRepository
+	Search() SearchRepository
+	Runs() RunRepository
+
+	// Transaction runs f atomically, repositories called with the context
+	// passed to f take part in it.
+	Transaction(ctx context.Context, f func(context.Context) error) error
+	// Migrate brings the schema up to date.
+	Migrate(ctx context.Context) error
+}
+
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	// AppliedAt is zero for pending migrations.
+	AppliedAt time.Time
+	// Unknown migrations were applied by a newer version.
+	Unknown bool
+}
+
+// Migrator is implemented by storages with a versioned schema.
+type Migrator interface {
+	MigrationStatus(ctx context.Context) ([]MigrationStatus, error)
+	// MigrateUp applies up to steps pending migrations, all when steps is not positive.
+	MigrateUp(ctx context.Context, steps int) (int, error)
+	// MigrateDown reverts up to steps applied migrations, all when steps is not positive.
+	MigrateDown(ctx context.Context, steps int) (int, error)
+}
@@ -0,0 +1,322 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	service "github.com/ldmonster/tts-parser/internal"
+	"github.com/ldmonster/tts-parser/internal/storage/gorm"
+	"github.com/ldmonster/tts-parser/internal/storage/memory"
+
+	uberzap "go.uber.org/zap"
+)
+
+// backends are the storages every repository has to behave alike in.
+var backends = map[string]func(t *testing.T) service.Storage{
+	"memory": func(t *testing.T) service.Storage {
+		return memory.NewStorage()
+	},
+	"sqlite": func(t *testing.T) service.Storage {
+		s, err := gorm.NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"), uberzap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = s.Migrate(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	},
+}
+
+func run(t *testing.T, test func(t *testing.T, ctx context.Context, s service.Storage)) {
+	for name, newStorage := range backends {
+		t.Run(name, func(t *testing.T) {
+			test(t, context.Background(), newStorage(t))
+		})
+	}
+}
+
+func TestModules(t *testing.T) {
+	run(t, func(t *testing.T, ctx context.Context, s service.Storage) {
+		_, err := s.Modules().Get(ctx, 1)
+		if !errors.Is(err, service.ErrModuleIsNotFound) {
+			t.Errorf("Get of an unknown module = %v, want ErrModuleIsNotFound", err)
+		}
+
+		mod := &service.Module{ID: 1, Name: "Chess", Tags: []string{"Classic"}, PlayerCounts: []int{2}}
+
+		err = s.Modules().Create(ctx, mod)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = s.Modules().Create(ctx, &service.Module{ID: 1, Name: "Chess again"})
+		if !errors.Is(err, service.ErrModuleConflict) {
+			t.Errorf("Create of an existing module = %v, want ErrModuleConflict", err)
+		}
+
+		err = s.Modules().Update(ctx, &service.Module{ID: 1, Note: "classic"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.Modules().Get(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Name != "Chess" || got.Note != "classic" || got.Status != service.ModuleStatusActive {
+			t.Errorf("Get after Update = %+v", got)
+		}
+
+		if !slices.Equal(got.Tags, []string{"Classic"}) || !slices.Equal(got.PlayerCounts, []int{2}) {
+			t.Errorf("Update changed tags or player counts: %v %v", got.Tags, got.PlayerCounts)
+		}
+
+		err = s.Modules().Upsert(ctx, &service.Module{ID: 1, Name: "Chess 2", Tags: []string{"Strategy"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = s.Modules().Get(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Name != "Chess 2" || !slices.Equal(got.Tags, []string{"Strategy"}) || len(got.PlayerCounts) != 0 {
+			t.Errorf("Get after Upsert = %+v", got)
+		}
+	})
+}
+
+func TestFiles(t *testing.T) {
+	run(t, func(t *testing.T, ctx context.Context, s service.Storage) {
+		for _, id := range []uint{1, 2} {
+			err := s.Modules().Create(ctx, &service.Module{ID: id, Name: "Module"})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// modules sharing a URL store it each
+		err := s.Files().BatchCreate(ctx,
+			service.File{ModuleID: 1, URL: "http://a/shared.png", Type: service.FileTypeImage},
+			service.File{ModuleID: 1, URL: "http://a/m.obj", Type: service.FileTypeModel},
+			service.File{ModuleID: 2, URL: "http://a/shared.png", Type: service.FileTypeImage},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = s.Files().BatchCreate(ctx, service.File{ModuleID: 1, URL: "http://a/shared.png", Type: service.FileTypeImage})
+		if !errors.Is(err, service.ErrFileConflict) {
+			t.Errorf("BatchCreate of a stored URL = %v, want ErrFileConflict", err)
+		}
+
+		files, err := s.Files().ListByModuleID(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) != 2 {
+			t.Fatalf("ListByModuleID = %+v, want 2 files", files)
+		}
+
+		files[0].Size = 42
+		files[0].ETag = "abc"
+
+		err = s.Files().UpdateContents(ctx, files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = s.Files().DeleteByIDs(ctx, files[1].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files, err = s.Files().ListByModuleID(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) != 1 || files[0].Size != 42 || files[0].ETag != "abc" {
+			t.Errorf("ListByModuleID after changes = %+v", files)
+		}
+
+		err = s.Files().DeleteByModuleID(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files, err = s.Files().List(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) != 1 || files[0].ModuleID != 1 {
+			t.Errorf("List after DeleteByModuleID = %+v", files)
+		}
+	})
+}
+
+func TestTransaction(t *testing.T) {
+	run(t, func(t *testing.T, ctx context.Context, s service.Storage) {
+		err := s.Modules().Create(ctx, &service.Module{ID: 1, Name: "Kept"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		failure := errors.New("failure")
+
+		err = s.Transaction(ctx, func(ctx context.Context) error {
+			err := s.Modules().Update(ctx, &service.Module{ID: 1, Name: "Changed"})
+			if err != nil {
+				return err
+			}
+
+			err = s.Modules().Create(ctx, &service.Module{ID: 2, Name: "Created"})
+			if err != nil {
+				return err
+			}
+
+			err = s.Files().BatchCreate(ctx, service.File{ModuleID: 2, URL: "http://a/b.png"})
+			if err != nil {
+				return err
+			}
+
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("Transaction = %v, want the error of f", err)
+		}
+
+		got, err := s.Modules().Get(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Name != "Kept" {
+			t.Errorf("Name after a failed transaction = %q, want %q", got.Name, "Kept")
+		}
+
+		_, err = s.Modules().Get(ctx, 2)
+		if !errors.Is(err, service.ErrModuleIsNotFound) {
+			t.Errorf("Get of a module created by a failed transaction = %v, want ErrModuleIsNotFound", err)
+		}
+
+		files, err := s.Files().List(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) != 0 {
+			t.Errorf("files created by a failed transaction = %+v", files)
+		}
+
+		err = s.Transaction(ctx, func(ctx context.Context) error {
+			return s.Modules().Update(ctx, &service.Module{ID: 1, Name: "Committed"})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = s.Modules().Get(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Name != "Committed" {
+			t.Errorf("Name after a transaction = %q, want %q", got.Name, "Committed")
+		}
+	})
+}
+
+func TestRuns(t *testing.T) {
+	run(t, func(t *testing.T, ctx context.Context, s service.Storage) {
+		_, err := s.Runs().Get(ctx, 1)
+		if !errors.Is(err, service.ErrRunIsNotFound) {
+			t.Errorf("Get of an unknown run = %v, want ErrRunIsNotFound", err)
+		}
+
+		err = s.Runs().Update(ctx, &service.Run{ID: 1})
+		if !errors.Is(err, service.ErrRunIsNotFound) {
+			t.Errorf("Update of an unknown run = %v, want ErrRunIsNotFound", err)
+		}
+
+		r := &service.Run{Command: "download", Modules: []uint{1, 2}}
+
+		err = s.Runs().Create(ctx, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.ID == 0 {
+			t.Fatal("Create did not set the ID")
+		}
+
+		got, err := s.Runs().Get(ctx, r.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Command != "download" || !slices.Equal(got.Modules, []uint{1, 2}) {
+			t.Errorf("Get = %+v", got)
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	run(t, func(t *testing.T, ctx context.Context, s service.Storage) {
+		err := s.Modules().Create(ctx, &service.Module{ID: 1, Name: "Dragons"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = s.Search().ReplaceModule(ctx, 1,
+			service.SearchDocument{ModuleID: 1, Kind: service.SearchDocumentModule, Title: "Dragons", Body: "fire and gold"},
+			service.SearchDocument{ModuleID: 1, Kind: service.SearchDocumentObject, GUID: "abc123", Title: "Gold coin", Body: ""},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hits, err := s.Search().Search(ctx, "gold", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(hits) != 2 || hits[0].Title != "Gold coin" {
+			t.Errorf("Search = %+v, want the title match first", hits)
+		}
+
+		hits, err = s.Search().Search(ctx, "gold fire", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(hits) != 1 || hits[0].Title != "Dragons" {
+			t.Errorf("Search of every word = %+v", hits)
+		}
+
+		err = s.Search().ReplaceModule(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hits, err = s.Search().Search(ctx, "gold", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(hits) != 0 {
+			t.Errorf("Search after ReplaceModule = %+v", hits)
+		}
+	})
+}
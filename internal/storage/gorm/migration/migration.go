@@ -0,0 +1,214 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	"gorm.io/gorm"
+)
+
+var ErrUnknownVersion = errors.New("database has migrations unknown to this version")
+
+// Migration changes the schema from the previous version to Version. Up and
+// Down run in a transaction together with the bookkeeping in schema_migrations.
+type Migration struct {
+	Version uint
+	Name    string
+
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+}
+
+// SchemaMigration is a row of an applied migration.
+type SchemaMigration struct {
+	Version   uint `gorm:"primarykey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New returns a migrator of the schema of this package, migrations must be
+// ordered by version.
+func New(db *gorm.DB) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Status returns every known migration, oldest first, with AppliedAt set for
+// the applied ones, followed by applied migrations unknown to this version.
+func (m *Migrator) Status(ctx context.Context) ([]service.MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]service.MigrationStatus, 0, len(m.migrations))
+
+	for _, mig := range m.migrations {
+		st := service.MigrationStatus{
+			Version: mig.Version,
+			Name:    mig.Name,
+		}
+
+		if row, ok := applied[mig.Version]; ok {
+			st.AppliedAt = row.AppliedAt
+			delete(applied, mig.Version)
+		}
+
+		result = append(result, st)
+	}
+
+	for _, version := range slices.Sorted(maps.Keys(applied)) {
+		result = append(result, service.MigrationStatus{
+			Version:   version,
+			Name:      applied[version].Name,
+			AppliedAt: applied[version].AppliedAt,
+			Unknown:   true,
+		})
+	}
+
+	return result, nil
+}
+
+// Pending returns the number of migrations Up would apply.
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+// Up applies up to steps pending migrations, all of them when steps is not
+// positive, and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context, steps int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for version := range applied {
+		if !slices.ContainsFunc(m.migrations, func(mig Migration) bool { return mig.Version == version }) {
+			return 0, fmt.Errorf("version %d: %w", version, ErrUnknownVersion)
+		}
+	}
+
+	done := 0
+
+	for _, mig := range m.migrations {
+		if steps > 0 && done == steps {
+			break
+		}
+
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+
+		err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := mig.Up(tx)
+			if err != nil {
+				return err
+			}
+
+			return tx.Create(&SchemaMigration{
+				Version:   mig.Version,
+				Name:      mig.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", mig.Version, mig.Name, err)
+		}
+
+		done++
+	}
+
+	return done, nil
+}
+
+// Down reverts up to steps applied migrations, newest first, and returns how
+// many were reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+
+	for _, mig := range slices.Backward(m.migrations) {
+		if steps > 0 && done == steps {
+			break
+		}
+
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+
+		err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			err := mig.Down(tx)
+			if err != nil {
+				return err
+			}
+
+			return tx.Delete(&SchemaMigration{Version: mig.Version}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("reverting migration %d %s: %w", mig.Version, mig.Name, err)
+		}
+
+		done++
+	}
+
+	return done, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[uint]SchemaMigration, error) {
+	db := m.db.WithContext(ctx)
+
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		err := db.Migrator().CreateTable(&SchemaMigration{})
+		if err != nil {
+			return nil, fmt.Errorf("create schema_migrations: %w", err)
+		}
+	}
+
+	rows := make([]SchemaMigration, 0)
+
+	err := db.Order("version").Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+
+	applied := make(map[uint]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
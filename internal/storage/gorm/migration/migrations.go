@@ -0,0 +1,582 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migrations are applied in order. Released migrations must not change, the
+// models below are snapshots of the schema they create and are independent of
+// the current model package.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		Up:      createInitialSchema,
+		Down:    dropInitialSchema,
+	},
+	{
+		Version: 2,
+		Name:    "files unique per module",
+		Up:      rebuildFilesUniquePerModule,
+		Down:    rebuildFilesUniqueURL,
+	},
+	{
+		Version: 3,
+		Name:    "module metadata",
+		Up:      addModuleMetadata,
+		Down:    dropModuleMetadata,
+	},
+	{
+		Version: 4,
+		Name:    "search documents",
+		Up:      createSearchDocuments,
+		Down:    dropSearchDocuments,
+	},
+	{
+		Version: 5,
+		Name:    "sync state",
+		Up:      addSyncState,
+		Down:    dropSyncState,
+	},
+	{
+		Version: 6,
+		Name:    "file failures",
+		Up:      addFileFailures,
+		Down:    dropFileFailures,
+	},
+	{
+		Version: 7,
+		Name:    "runs",
+		Up:      createRuns,
+		Down:    dropRuns,
+	},
+	{
+		Version: 8,
+		Name:    "file etags",
+		Up:      addFileETags,
+		Down:    dropFileETags,
+	},
+}
+
+type moduleV1 struct {
+	ID uint `gorm:"primarykey"`
+
+	Name          string
+	EpochTime     uint
+	VersionNumber string
+
+	SaveEpochTime int
+
+	WorkshopSize    int64
+	WorkshopModTime time.Time
+	WorkshopHash    string
+
+	Status     string `gorm:"default:active;index:idx_modules_status"`
+	LastSeenAt time.Time
+	RemovedAt  time.Time
+}
+
+func (moduleV1) TableName() string {
+	return "modules"
+}
+
+type fileV1 struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID  uint   `gorm:"column:module_id"`
+	FileType  string `gorm:"column:file_type;size:16;not null"`
+	URL       string `gorm:"unique;not null;column:url"`
+	Extension string `gorm:"column:extension"`
+}
+
+func (fileV1) TableName() string {
+	return "files"
+}
+
+type scriptV1 struct {
+	ID uint `gorm:"primarykey"`
+
+	Hash    string `gorm:"unique;not null;column:hash"`
+	Size    int    `gorm:"column:size"`
+	Content string `gorm:"column:content"`
+}
+
+func (scriptV1) TableName() string {
+	return "scripts"
+}
+
+type scriptUsageV1 struct {
+	ID uint `gorm:"primarykey"`
+
+	ScriptID uint   `gorm:"index:idx_script_usages_script_id;not null;column:script_id"`
+	ModuleID uint   `gorm:"index:idx_script_usages_module_id;not null;column:module_id"`
+	GUID     string `gorm:"column:guid"`
+	Nickname string `gorm:"column:nickname"`
+	Path     string `gorm:"column:path"`
+}
+
+func (scriptUsageV1) TableName() string {
+	return "script_usages"
+}
+
+type scriptIncludeV1 struct {
+	ID uint `gorm:"primarykey"`
+
+	BundleID uint   `gorm:"uniqueIndex:idx_script_includes_bundle_name;not null;column:bundle_id"`
+	Name     string `gorm:"uniqueIndex:idx_script_includes_bundle_name;not null;column:name"`
+	ScriptID uint   `gorm:"index:idx_script_includes_script_id;not null;column:script_id"`
+}
+
+func (scriptIncludeV1) TableName() string {
+	return "script_includes"
+}
+
+type moduleVersionV1 struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID      uint   `gorm:"uniqueIndex:idx_module_versions_module_hash;not null;column:module_id"`
+	Hash          string `gorm:"uniqueIndex:idx_module_versions_module_hash;not null;column:hash"`
+	SaveName      string `gorm:"column:save_name"`
+	VersionNumber string `gorm:"column:version_number"`
+	EpochTime     uint   `gorm:"column:epoch_time"`
+	SaveEpochTime int    `gorm:"column:save_epoch_time"`
+	Size          int64  `gorm:"column:size"`
+	Data          []byte `gorm:"column:data"`
+
+	CreatedAt time.Time
+}
+
+func (moduleVersionV1) TableName() string {
+	return "module_versions"
+}
+
+func initialSchema() []any {
+	return []any{&moduleV1{}, &fileV1{}, &scriptV1{}, &scriptUsageV1{}, &scriptIncludeV1{}, &moduleVersionV1{}}
+}
+
+// createInitialSchema creates the tables the releases before versioned
+// migrations kept up to date with AutoMigrate. Databases of those releases
+// are adopted: existing tables only get the columns and indexes they lack,
+// their column types are left as they are.
+func createInitialSchema(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	for _, model := range initialSchema() {
+		if !m.HasTable(model) {
+			err := m.CreateTable(model)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: tx}
+
+		err := stmt.Parse(model)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range stmt.Schema.DBNames {
+			if m.HasColumn(model, name) {
+				continue
+			}
+
+			err = m.AddColumn(model, name)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, idx := range stmt.Schema.ParseIndexes() {
+			if m.HasIndex(model, idx.Name) {
+				continue
+			}
+
+			err = m.CreateIndex(model, idx.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func dropInitialSchema(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(initialSchema()...)
+}
+
+// fileV2 fixes the type of file_type, declared as a non-existent SQL type by
+// the first releases, and stores files per module: modules sharing a URL each
+// get their row.
+type fileV2 struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID  uint   `gorm:"uniqueIndex:idx_files_module_url;not null;column:module_id"`
+	FileType  string `gorm:"column:file_type;size:16;not null"`
+	URL       string `gorm:"uniqueIndex:idx_files_module_url;not null;column:url"`
+	Extension string `gorm:"column:extension"`
+}
+
+func (fileV2) TableName() string {
+	return "files"
+}
+
+func rebuildFilesUniquePerModule(tx *gorm.DB) error {
+	return rebuildFiles(tx, &fileV2{}, "SELECT id, module_id, file_type, url, extension FROM files_old")
+}
+
+// rebuildFilesUniqueURL keeps the oldest row of every URL, rows of other
+// modules sharing it are lost.
+func rebuildFilesUniqueURL(tx *gorm.DB) error {
+	return rebuildFiles(tx, &fileV1{}, `SELECT id, module_id, file_type, url, extension FROM files_old
+		WHERE id IN (SELECT MIN(id) FROM files_old GROUP BY url)`)
+}
+
+// rebuildFiles replaces the files table with one created from model and
+// filled by query from files_old. SQLite cannot alter column types or
+// constraints in place.
+func rebuildFiles(tx *gorm.DB, model any, query string) error {
+	m := tx.Migrator()
+
+	err := m.RenameTable("files", "files_old")
+	if err != nil {
+		return err
+	}
+
+	err = m.CreateTable(model)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Exec("INSERT INTO files (id, module_id, file_type, url, extension) " + query).Error
+	if err != nil {
+		return err
+	}
+
+	err = m.DropTable("files_old")
+	if err != nil {
+		return err
+	}
+
+	// ids were copied, move the sequence past them
+	if tx.Dialector.Name() == "postgres" {
+		err = tx.Exec("SELECT setval(pg_get_serial_sequence('files', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM files").Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type moduleMetadataV3 struct {
+	ID uint `gorm:"primarykey"`
+
+	GameMode       string
+	GameType       string
+	GameComplexity string
+	MinPlayingTime int
+	MaxPlayingTime int
+	Table          string `gorm:"column:table_name"`
+	Sky            string
+	Note           string
+}
+
+func (moduleMetadataV3) TableName() string {
+	return "modules"
+}
+
+var moduleMetadataColumnsV3 = []string{
+	"game_mode", "game_type", "game_complexity", "min_playing_time", "max_playing_time", "table_name", "sky", "note",
+}
+
+type moduleTagV3 struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID uint   `gorm:"uniqueIndex:idx_module_tags_module_tag;not null;column:module_id"`
+	Tag      string `gorm:"uniqueIndex:idx_module_tags_module_tag;index:idx_module_tags_tag;not null;column:tag"`
+}
+
+func (moduleTagV3) TableName() string {
+	return "module_tags"
+}
+
+type modulePlayerCountV3 struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID uint `gorm:"uniqueIndex:idx_module_player_counts_module_players;not null;column:module_id"`
+	Players  int  `gorm:"uniqueIndex:idx_module_player_counts_module_players;index:idx_module_player_counts_players;not null;column:players"`
+}
+
+func (modulePlayerCountV3) TableName() string {
+	return "module_player_counts"
+}
+
+func addModuleMetadata(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	for _, column := range moduleMetadataColumnsV3 {
+		err := m.AddColumn(&moduleMetadataV3{}, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := m.CreateTable(&moduleTagV3{}, &modulePlayerCountV3{})
+	if err != nil {
+		return err
+	}
+
+	return forgetWorkshopState(tx)
+}
+
+func dropModuleMetadata(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	err := m.DropTable(&moduleTagV3{}, &modulePlayerCountV3{})
+	if err != nil {
+		return err
+	}
+
+	return dropColumns(tx, "modules", moduleMetadataColumnsV3...)
+}
+
+// dropColumns drops columns in place. The SQLite migrator of gorm rebuilds the
+// table instead, losing its indexes.
+func dropColumns(tx *gorm.DB, table string, columns ...string) error {
+	for _, column := range columns {
+		err := tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: column}).Error
+		if err != nil {
+			return fmt.Errorf("drop column %s.%s: %w", table, column, err)
+		}
+	}
+
+	return nil
+}
+
+// forgetWorkshopState makes the next download parse every stored module again,
+// backfilling data added by a migration.
+func forgetWorkshopState(tx *gorm.DB) error {
+	return tx.Exec("UPDATE modules SET workshop_size = 0, workshop_hash = ''").Error
+}
+
+type searchDocumentV4 struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID uint   `gorm:"index;not null;column:module_id"`
+	Kind     string `gorm:"size:16;not null;column:kind"`
+	GUID     string `gorm:"column:guid"`
+	Path     string `gorm:"column:path"`
+	Title    string `gorm:"column:title"`
+	Body     string `gorm:"column:body"`
+}
+
+func (searchDocumentV4) TableName() string {
+	return "search_documents"
+}
+
+// createSearchDocuments keeps a weighted tsvector of each document in
+// PostgreSQL, the SQLite FTS5 index is created by the repository because FTS5
+// support depends on the build.
+func createSearchDocuments(tx *gorm.DB) error {
+	err := tx.Migrator().CreateTable(&searchDocumentV4{})
+	if err != nil {
+		return err
+	}
+
+	if tx.Dialector.Name() == "postgres" {
+		err = tx.Exec(`ALTER TABLE search_documents ADD COLUMN tsv tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(body, '')), 'B')) STORED`).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("CREATE INDEX idx_search_documents_tsv ON search_documents USING GIN (tsv)").Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return forgetWorkshopState(tx)
+}
+
+func dropSearchDocuments(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	// the FTS5 index exists only in SQLite databases used by builds with FTS5
+	if m.HasTable("search_fts") {
+		err := tx.Exec("DROP TABLE search_fts").Error
+		if err != nil {
+			return fmt.Errorf("drop search_fts, revert with a build using the sqlite_fts5 tag: %w", err)
+		}
+	}
+
+	return m.DropTable(&searchDocumentV4{})
+}
+
+type moduleSyncV5 struct {
+	SyncedAt    time.Time
+	FailedFiles int
+}
+
+func (moduleSyncV5) TableName() string {
+	return "modules"
+}
+
+type fileSizeV5 struct {
+	Size int64 `gorm:"not null;default:0;column:size"`
+}
+
+func (fileSizeV5) TableName() string {
+	return "files"
+}
+
+func addSyncState(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	for _, column := range []string{"SyncedAt", "FailedFiles"} {
+		err := m.AddColumn(&moduleSyncV5{}, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := m.AddColumn(&fileSizeV5{}, "Size")
+	if err != nil {
+		return err
+	}
+
+	return forgetWorkshopState(tx)
+}
+
+func dropSyncState(tx *gorm.DB) error {
+	err := dropColumns(tx, "files", "size")
+	if err != nil {
+		return err
+	}
+
+	return dropColumns(tx, "modules", "synced_at", "failed_files")
+}
+
+type fileCreatedAtV6 struct {
+	CreatedAt *time.Time
+}
+
+func (fileCreatedAtV6) TableName() string {
+	return "files"
+}
+
+type fileFailureV6 struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID uint      `gorm:"index;not null;column:module_id"`
+	FileType string    `gorm:"column:file_type;size:16;not null"`
+	URL      string    `gorm:"not null;column:url"`
+	Error    string    `gorm:"column:error"`
+	FailedAt time.Time `gorm:"column:failed_at"`
+}
+
+func (fileFailureV6) TableName() string {
+	return "file_failures"
+}
+
+// addFileFailures tracks when files are stored, existing files keep a null
+// creation time.
+func addFileFailures(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	err := m.AddColumn(&fileCreatedAtV6{}, "CreatedAt")
+	if err != nil {
+		return err
+	}
+
+	return m.CreateTable(&fileFailureV6{})
+}
+
+func dropFileFailures(tx *gorm.DB) error {
+	m := tx.Migrator()
+
+	err := m.DropTable(&fileFailureV6{})
+	if err != nil {
+		return err
+	}
+
+	return dropColumns(tx, "files", "created_at")
+}
+
+type runV7 struct {
+	ID uint `gorm:"primarykey"`
+
+	Command string
+	Modules string
+	Status  string `gorm:"size:16;not null"`
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	Parsed  int
+	Skipped int
+	Failed  int
+
+	FilesDownloaded int
+	FilesExisting   int
+	FilesFailed     int
+	Bytes           int64
+}
+
+func (runV7) TableName() string {
+	return "runs"
+}
+
+type runModuleV7 struct {
+	ID uint `gorm:"primarykey"`
+
+	RunID    uint `gorm:"index;not null;column:run_id"`
+	ModuleID uint `gorm:"not null;column:module_id"`
+	Name     string
+
+	FilesDownloaded int
+	FilesExisting   int
+	FilesFailed     int
+	FilesAdded      int
+	FilesRemoved    int
+	Bytes           int64
+	DurationMS      int64 `gorm:"column:duration_ms"`
+	Error           string
+}
+
+func (runModuleV7) TableName() string {
+	return "run_modules"
+}
+
+func createRuns(tx *gorm.DB) error {
+	return tx.Migrator().CreateTable(&runV7{}, &runModuleV7{})
+}
+
+func dropRuns(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&runModuleV7{}, &runV7{})
+}
+
+type fileETagV8 struct {
+	ETag string `gorm:"not null;default:'';column:etag"`
+}
+
+func (fileETagV8) TableName() string {
+	return "files"
+}
+
+func addFileETags(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&fileETagV8{}, "ETag")
+}
+
+func dropFileETags(tx *gorm.DB) error {
+	return dropColumns(tx, "files", "etag")
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"database/sql/driver"
+	"time"
 
 	service "github.com/ldmonster/tts-parser/internal"
 )
@@ -66,10 +67,21 @@ func (st FileType) Value() (driver.Value, error) {
 type File struct {
 	ID uint `gorm:"primarykey"`
 
-	ModuleID  uint     `gorm:"column:module_id"`
-	FileType  FileType `gorm:"column:file_type;type:file_type;not null"`
-	URL       string   `gorm:"unique;not null;column:url"`
+	ModuleID  uint     `gorm:"uniqueIndex:idx_files_module_url;not null;column:module_id"`
+	FileType  FileType `gorm:"column:file_type;size:16;not null"`
+	URL       string   `gorm:"uniqueIndex:idx_files_module_url;not null;column:url"`
 	Extension string   `gorm:"column:extension"`
+	Size      int64    `gorm:"not null;default:0;column:size"`
+	ETag      string   `gorm:"not null;default:'';column:etag"`
+
+	// CreatedAt is null for files stored before it was tracked.
+	CreatedAt *time.Time
+}
+
+type ModuleFileStats struct {
+	ModuleID uint  `gorm:"column:module_id"`
+	Files    int   `gorm:"column:files"`
+	Bytes    int64 `gorm:"column:bytes"`
 }
 
 func RemapFromServiceFiles(input ...service.File) []File {
@@ -84,10 +96,14 @@ func RemapFromServiceFiles(input ...service.File) []File {
 
 func RemapFromServiceFile(input *service.File) *File {
 	return &File{
+		ID:        input.ID,
 		ModuleID:  input.ModuleID,
 		FileType:  remapFromServiceFileType(input.Type),
 		URL:       input.URL,
 		Extension: input.Extension,
+		Size:      input.Size,
+		ETag:      input.ETag,
+		CreatedAt: optionalTime(input.CreatedAt),
 	}
 }
 
@@ -103,9 +119,44 @@ func RemapToServiceFiles(input ...File) []service.File {
 
 func RemapToServiceFile(input *File) *service.File {
 	return &service.File{
+		ID:        input.ID,
 		ModuleID:  input.ModuleID,
 		Type:      remapToServiceFileType(input.FileType),
 		URL:       input.URL,
 		Extension: input.Extension,
+		Size:      input.Size,
+		ETag:      input.ETag,
+		CreatedAt: derefTime(input.CreatedAt),
 	}
 }
+
+func RemapToServiceModuleFileStats(input ...ModuleFileStats) []service.ModuleFileStats {
+	result := make([]service.ModuleFileStats, 0, len(input))
+
+	for _, s := range input {
+		result = append(result, service.ModuleFileStats{
+			ModuleID: s.ModuleID,
+			Files:    s.Files,
+			Bytes:    s.Bytes,
+		})
+	}
+
+	return result
+}
+
+// optionalTime stores zero times as null.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+type FileFailure struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID uint      `gorm:"index;not null;column:module_id"`
+	FileType FileType  `gorm:"column:file_type;size:16;not null"`
+	URL      string    `gorm:"not null;column:url"`
+	Error    string    `gorm:"column:error"`
+	FailedAt time.Time `gorm:"column:failed_at"`
+}
+
+func RemapFromServiceFileFailures(input ...service.FileFailure) []FileFailure {
+	result := make([]FileFailure, 0, len(input))
+
+	for _, f := range input {
+		result = append(result, FileFailure{
+			ID:       f.ID,
+			ModuleID: f.ModuleID,
+			FileType: remapFromServiceFileType(f.Type),
+			URL:      f.URL,
+			Error:    f.Error,
+			FailedAt: f.FailedAt,
+		})
+	}
+
+	return result
+}
+
+func RemapToServiceFileFailures(input ...FileFailure) []service.FileFailure {
+	result := make([]service.FileFailure, 0, len(input))
+
+	for _, f := range input {
+		result = append(result, service.FileFailure{
+			ID:       f.ID,
+			ModuleID: f.ModuleID,
+			Type:     remapToServiceFileType(f.FileType),
+			URL:      f.URL,
+			Error:    f.Error,
+			FailedAt: f.FailedAt,
+		})
+	}
+
+	return result
+}
@@ -1,10 +1,148 @@
 package model
 
+import (
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
 type Module struct {
 	ID uint `gorm:"primarykey"`
 
 	Name          string
 	EpochTime     uint
 	VersionNumber string
-	// TelegramID       int64 `gorm:"unique;not null"`
+
+	// SaveEpochTime is the EpochTime written by TTS into the save.
+	SaveEpochTime int
+
+	// Workshop file state of the last parse, unchanged files are skipped.
+	WorkshopSize    int64
+	WorkshopModTime time.Time
+	WorkshopHash    string
+
+	Status     service.ModuleStatus `gorm:"default:active;index"`
+	LastSeenAt time.Time
+	RemovedAt  time.Time
+
+	SyncedAt    time.Time
+	FailedFiles int
+
+	GameMode       string
+	GameType       string
+	GameComplexity string
+	MinPlayingTime int
+	MaxPlayingTime int
+	Table          string `gorm:"column:table_name"`
+	Sky            string
+	Note           string
+
+	Tags         []ModuleTag         `gorm:"foreignKey:ModuleID"`
+	PlayerCounts []ModulePlayerCount `gorm:"foreignKey:ModuleID"`
+}
+
+type ModuleTag struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID uint   `gorm:"uniqueIndex:idx_module_tags_module_tag;not null;column:module_id"`
+	Tag      string `gorm:"uniqueIndex:idx_module_tags_module_tag;index:idx_module_tags_tag;not null;column:tag"`
+}
+
+type ModulePlayerCount struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID uint `gorm:"uniqueIndex:idx_module_player_counts_module_players;not null;column:module_id"`
+	Players  int  `gorm:"uniqueIndex:idx_module_player_counts_module_players;index:idx_module_player_counts_players;not null;column:players"`
+}
+
+func RemapFromServiceModuleTags(id uint, tags ...string) []ModuleTag {
+	result := make([]ModuleTag, 0, len(tags))
+
+	for _, t := range tags {
+		result = append(result, ModuleTag{ModuleID: id, Tag: t})
+	}
+
+	return result
+}
+
+func RemapFromServiceModulePlayerCounts(id uint, counts ...int) []ModulePlayerCount {
+	result := make([]ModulePlayerCount, 0, len(counts))
+
+	for _, n := range counts {
+		result = append(result, ModulePlayerCount{ModuleID: id, Players: n})
+	}
+
+	return result
+}
+
+func RemapFromServiceModule(input *service.Module) *Module {
+	return &Module{
+		ID:              input.ID,
+		Name:            input.Name,
+		EpochTime:       input.EpochTime,
+		VersionNumber:   input.VersionNumber,
+		SaveEpochTime:   input.SaveEpochTime,
+		WorkshopSize:    input.WorkshopSize,
+		WorkshopModTime: input.WorkshopModTime,
+		WorkshopHash:    input.WorkshopHash,
+		Status:          input.Status,
+		LastSeenAt:      input.LastSeenAt,
+		RemovedAt:       input.RemovedAt,
+		SyncedAt:        input.SyncedAt,
+		FailedFiles:     input.FailedFiles,
+		GameMode:        input.GameMode,
+		GameType:        input.GameType,
+		GameComplexity:  input.GameComplexity,
+		MinPlayingTime:  input.MinPlayingTime,
+		MaxPlayingTime:  input.MaxPlayingTime,
+		Table:           input.Table,
+		Sky:             input.Sky,
+		Note:            input.Note,
+	}
+}
+
+func RemapToServiceModules(input ...Module) []service.Module {
+	result := make([]service.Module, 0, len(input))
+
+	for _, m := range input {
+		result = append(result, *RemapToServiceModule(&m))
+	}
+
+	return result
+}
+
+func RemapToServiceModule(input *Module) *service.Module {
+	m := &service.Module{
+		ID:              input.ID,
+		Name:            input.Name,
+		EpochTime:       input.EpochTime,
+		VersionNumber:   input.VersionNumber,
+		SaveEpochTime:   input.SaveEpochTime,
+		WorkshopSize:    input.WorkshopSize,
+		WorkshopModTime: input.WorkshopModTime,
+		WorkshopHash:    input.WorkshopHash,
+		Status:          input.Status,
+		LastSeenAt:      input.LastSeenAt,
+		RemovedAt:       input.RemovedAt,
+		SyncedAt:        input.SyncedAt,
+		FailedFiles:     input.FailedFiles,
+		GameMode:        input.GameMode,
+		GameType:        input.GameType,
+		GameComplexity:  input.GameComplexity,
+		MinPlayingTime:  input.MinPlayingTime,
+		MaxPlayingTime:  input.MaxPlayingTime,
+		Table:           input.Table,
+		Sky:             input.Sky,
+		Note:            input.Note,
+	}
+
+	for _, t := range input.Tags {
+		m.Tags = append(m.Tags, t.Tag)
+	}
+
+	for _, pc := range input.PlayerCounts {
+		m.PlayerCounts = append(m.PlayerCounts, pc.Players)
+	}
+
+	return m
 }
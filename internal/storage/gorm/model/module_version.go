@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+// ModuleVersion is a distinct workshop JSON of a module, stored gzip-compressed.
+type ModuleVersion struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID      uint   `gorm:"uniqueIndex:idx_module_versions_module_hash;not null;column:module_id"`
+	Hash          string `gorm:"uniqueIndex:idx_module_versions_module_hash;not null;column:hash"`
+	SaveName      string `gorm:"column:save_name"`
+	VersionNumber string `gorm:"column:version_number"`
+	EpochTime     uint   `gorm:"column:epoch_time"`
+	SaveEpochTime int    `gorm:"column:save_epoch_time"`
+	Size          int64  `gorm:"column:size"`
+	Data          []byte `gorm:"column:data"`
+
+	CreatedAt time.Time
+}
+
+func RemapFromServiceModuleVersion(input *service.ModuleVersion) *ModuleVersion {
+	return &ModuleVersion{
+		ID:            input.ID,
+		ModuleID:      input.ModuleID,
+		Hash:          input.Hash,
+		SaveName:      input.SaveName,
+		VersionNumber: input.VersionNumber,
+		EpochTime:     input.EpochTime,
+		SaveEpochTime: input.SaveEpochTime,
+		Size:          input.Size,
+		Data:          input.Data,
+		CreatedAt:     input.CreatedAt,
+	}
+}
+
+func RemapToServiceModuleVersions(input ...ModuleVersion) []service.ModuleVersion {
+	result := make([]service.ModuleVersion, 0, len(input))
+
+	for _, v := range input {
+		result = append(result, *RemapToServiceModuleVersion(&v))
+	}
+
+	return result
+}
+
+func RemapToServiceModuleVersion(input *ModuleVersion) *service.ModuleVersion {
+	return &service.ModuleVersion{
+		ID:            input.ID,
+		ModuleID:      input.ModuleID,
+		Hash:          input.Hash,
+		SaveName:      input.SaveName,
+		VersionNumber: input.VersionNumber,
+		EpochTime:     input.EpochTime,
+		SaveEpochTime: input.SaveEpochTime,
+		Size:          input.Size,
+		Data:          input.Data,
+		CreatedAt:     input.CreatedAt,
+	}
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+type Run struct {
+	ID uint `gorm:"primarykey"`
+
+	Command string
+	// Modules are comma separated module IDs.
+	Modules string
+	Status  service.RunStatus `gorm:"size:16;not null"`
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	Parsed  int
+	Skipped int
+	Failed  int
+
+	FilesDownloaded int
+	FilesExisting   int
+	FilesFailed     int
+	Bytes           int64
+}
+
+type RunModule struct {
+	ID uint `gorm:"primarykey"`
+
+	RunID    uint `gorm:"index;not null;column:run_id"`
+	ModuleID uint `gorm:"not null;column:module_id"`
+	Name     string
+
+	FilesDownloaded int
+	FilesExisting   int
+	FilesFailed     int
+	FilesAdded      int
+	FilesRemoved    int
+	Bytes           int64
+	DurationMS      int64 `gorm:"column:duration_ms"`
+	Error           string
+}
+
+func RemapFromServiceRun(input *service.Run) *Run {
+	modules := make([]string, 0, len(input.Modules))
+	for _, id := range input.Modules {
+		modules = append(modules, strconv.FormatUint(uint64(id), 10))
+	}
+
+	return &Run{
+		ID:              input.ID,
+		Command:         input.Command,
+		Modules:         strings.Join(modules, ","),
+		Status:          input.Status,
+		StartedAt:       input.StartedAt,
+		FinishedAt:      input.FinishedAt,
+		Parsed:          input.Parsed,
+		Skipped:         input.Skipped,
+		Failed:          input.Failed,
+		FilesDownloaded: input.FilesDownloaded,
+		FilesExisting:   input.FilesExisting,
+		FilesFailed:     input.FilesFailed,
+		Bytes:           input.Bytes,
+	}
+}
+
+func RemapToServiceRuns(input ...Run) []service.Run {
+	result := make([]service.Run, 0, len(input))
+
+	for _, r := range input {
+		result = append(result, *RemapToServiceRun(&r))
+	}
+
+	return result
+}
+
+func RemapToServiceRun(input *Run) *service.Run {
+	r := &service.Run{
+		ID:              input.ID,
+		Command:         input.Command,
+		Status:          input.Status,
+		StartedAt:       input.StartedAt,
+		FinishedAt:      input.FinishedAt,
+		Parsed:          input.Parsed,
+		Skipped:         input.Skipped,
+		Failed:          input.Failed,
+		FilesDownloaded: input.FilesDownloaded,
+		FilesExisting:   input.FilesExisting,
+		FilesFailed:     input.FilesFailed,
+		Bytes:           input.Bytes,
+	}
+
+	for _, part := range strings.Split(input.Modules, ",") {
+		if id, err := strconv.ParseUint(part, 10, 0); err == nil {
+			r.Modules = append(r.Modules, uint(id))
+		}
+	}
+
+	return r
+}
+
+func RemapFromServiceRunModules(input ...service.RunModule) []RunModule {
+	result := make([]RunModule, 0, len(input))
+
+	for _, m := range input {
+		result = append(result, RunModule{
+			ID:              m.ID,
+			RunID:           m.RunID,
+			ModuleID:        m.ModuleID,
+			Name:            m.Name,
+			FilesDownloaded: m.FilesDownloaded,
+			FilesExisting:   m.FilesExisting,
+			FilesFailed:     m.FilesFailed,
+			FilesAdded:      m.FilesAdded,
+			FilesRemoved:    m.FilesRemoved,
+			Bytes:           m.Bytes,
+			DurationMS:      m.Duration.Milliseconds(),
+			Error:           m.Error,
+		})
+	}
+
+	return result
+}
+
+func RemapToServiceRunModules(input ...RunModule) []service.RunModule {
+	result := make([]service.RunModule, 0, len(input))
+
+	for _, m := range input {
+		result = append(result, service.RunModule{
+			ID:              m.ID,
+			RunID:           m.RunID,
+			ModuleID:        m.ModuleID,
+			Name:            m.Name,
+			FilesDownloaded: m.FilesDownloaded,
+			FilesExisting:   m.FilesExisting,
+			FilesFailed:     m.FilesFailed,
+			FilesAdded:      m.FilesAdded,
+			FilesRemoved:    m.FilesRemoved,
+			Bytes:           m.Bytes,
+			Duration:        time.Duration(m.DurationMS) * time.Millisecond,
+			Error:           m.Error,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+type Script struct {
+	ID uint `gorm:"primarykey"`
+
+	Hash    string `gorm:"unique;not null;column:hash"`
+	Size    int    `gorm:"column:size"`
+	Content string `gorm:"column:content"`
+}
+
+type ScriptUsage struct {
+	ID uint `gorm:"primarykey"`
+
+	ScriptID uint   `gorm:"index;not null;column:script_id"`
+	ModuleID uint   `gorm:"index;not null;column:module_id"`
+	GUID     string `gorm:"column:guid"`
+	Nickname string `gorm:"column:nickname"`
+	Path     string `gorm:"column:path"`
+}
+
+// ScriptInclude links a luabundle script to the modules it was built from.
+type ScriptInclude struct {
+	ID uint `gorm:"primarykey"`
+
+	BundleID uint   `gorm:"uniqueIndex:idx_script_includes_bundle_name;not null;column:bundle_id"`
+	Name     string `gorm:"uniqueIndex:idx_script_includes_bundle_name;not null;column:name"`
+	ScriptID uint   `gorm:"index;not null;column:script_id"`
+}
+
+// ScriptMatch is a usage of a script found by a content search.
+type ScriptMatch struct {
+	ScriptUsage
+
+	Hash string `gorm:"column:hash"`
+	Size int    `gorm:"column:size"`
+}
+
+func RemapFromServiceScript(input *service.Script) *Script {
+	return &Script{
+		ID:      input.ID,
+		Hash:    input.Hash,
+		Size:    input.Size,
+		Content: input.Content,
+	}
+}
+
+func RemapFromServiceScriptUsages(input ...service.ScriptUsage) []ScriptUsage {
+	result := make([]ScriptUsage, 0, len(input))
+
+	for _, u := range input {
+		result = append(result, ScriptUsage{
+			ID:       u.ID,
+			ScriptID: u.ScriptID,
+			ModuleID: u.ModuleID,
+			GUID:     u.GUID,
+			Nickname: u.Nickname,
+			Path:     u.Path,
+		})
+	}
+
+	return result
+}
+
+func RemapFromServiceScriptIncludes(input ...service.ScriptInclude) []ScriptInclude {
+	result := make([]ScriptInclude, 0, len(input))
+
+	for _, i := range input {
+		result = append(result, ScriptInclude{
+			ID:       i.ID,
+			BundleID: i.BundleID,
+			Name:     i.Name,
+			ScriptID: i.ScriptID,
+		})
+	}
+
+	return result
+}
+
+func RemapToServiceScriptMatches(input ...ScriptMatch) []service.ScriptMatch {
+	result := make([]service.ScriptMatch, 0, len(input))
+
+	for _, m := range input {
+		result = append(result, service.ScriptMatch{
+			ScriptUsage: service.ScriptUsage{
+				ID:       m.ID,
+				ScriptID: m.ScriptID,
+				ModuleID: m.ModuleID,
+				GUID:     m.GUID,
+				Nickname: m.Nickname,
+				Path:     m.Path,
+			},
+			Hash: m.Hash,
+			Size: m.Size,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+type SearchDocument struct {
+	ID uint `gorm:"primarykey"`
+
+	ModuleID uint                       `gorm:"index;not null;column:module_id"`
+	Kind     service.SearchDocumentKind `gorm:"size:16;not null;column:kind"`
+	GUID     string                     `gorm:"column:guid"`
+	Path     string                     `gorm:"column:path"`
+	Title    string                     `gorm:"column:title"`
+	Body     string                     `gorm:"column:body"`
+}
+
+// SearchHit is a document matching a search query.
+type SearchHit struct {
+	SearchDocument
+
+	Rank float64 `gorm:"column:rank"`
+}
+
+func RemapFromServiceSearchDocuments(input ...service.SearchDocument) []SearchDocument {
+	result := make([]SearchDocument, 0, len(input))
+
+	for _, d := range input {
+		result = append(result, SearchDocument{
+			ID:       d.ID,
+			ModuleID: d.ModuleID,
+			Kind:     d.Kind,
+			GUID:     d.GUID,
+			Path:     d.Path,
+			Title:    d.Title,
+			Body:     d.Body,
+		})
+	}
+
+	return result
+}
+
+func RemapToServiceSearchHits(input ...SearchHit) []service.SearchHit {
+	result := make([]service.SearchHit, 0, len(input))
+
+	for _, h := range input {
+		result = append(result, service.SearchHit{
+			SearchDocument: service.SearchDocument{
+				ID:       h.ID,
+				ModuleID: h.ModuleID,
+				Kind:     h.Kind,
+				GUID:     h.GUID,
+				Path:     h.Path,
+				Title:    h.Title,
+				Body:     h.Body,
+			},
+			Rank: h.Rank,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// isUniqueViolation reports whether err is a unique constraint violation. The
+// dialector translates driver errors, SQLite and PostgreSQL codes alike, into
+// gorm.ErrDuplicatedKey.
+func isUniqueViolation(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
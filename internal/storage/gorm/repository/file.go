@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
@@ -10,11 +9,12 @@ import (
 
 	service "github.com/ldmonster/tts-parser/internal"
 
-	"github.com/mattn/go-sqlite3"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+var _ service.FileRepository = (*File)(nil)
+
 type File struct {
 	DB *gorm.DB
 }
@@ -25,79 +25,35 @@ func NewFile(db *gorm.DB) *File {
 	}
 }
 
-func (f *File) AutoMigrate(ctx context.Context) error {
-	return session.DB(ctx, f.DB).Omit(clause.Associations).AutoMigrate(&model.File{})
-}
-
-func (f *File) Get(ctx context.Context, id uint) (*model.File, error) {
-	existing := &model.File{
-		ID: id,
-	}
-
-	db := session.DB(ctx, f.DB).Omit(clause.Associations).First(existing)
-
-	return existing, db.Error
-}
-
-func (f *File) GetByTelegramID(ctx context.Context, telegramID int64) (*model.File, error) {
-	existing := &model.File{}
-
-	db := session.DB(ctx, f.DB).Omit(clause.Associations).Where("telegram_id = ?", telegramID).First(existing)
-	if db.Error != nil && errors.Is(db.Error, gorm.ErrRecordNotFound) {
-		return nil, service.ErrFileIsNotFound
-	}
-
-	if db.Error != nil {
-		return nil, db.Error
-	}
-
-	return existing, nil
-}
-
-func (f *File) ListByModuleID(ctx context.Context, id uint) ([]model.File, error) {
+func (f *File) ListByModuleID(ctx context.Context, id uint) ([]service.File, error) {
 	existing := make([]model.File, 0, 1)
 
 	db := session.DB(ctx, f.DB).Omit(clause.Associations).Where("module_id = ?", id).Find(&existing)
-
-	if len(existing) > 0 {
-		if existing[0].FileType == "" {
-			panic("existing type are empty")
-		}
+	if db.Error != nil {
+		return nil, db.Error
 	}
 
-	return existing, db.Error
+	return model.RemapToServiceFiles(existing...), nil
 }
 
-func (f *File) List(ctx context.Context) ([]model.File, error) {
+func (f *File) List(ctx context.Context) ([]service.File, error) {
 	existing := make([]model.File, 0, 1)
 
 	db := session.DB(ctx, f.DB).Omit(clause.Associations).Find(&existing)
-
-	return existing, db.Error
-}
-
-func (f *File) Create(ctx context.Context, file *model.File) (*model.File, error) {
-	db := session.DB(ctx, f.DB).Omit(clause.Associations).Create(file)
-	sqliteErr := sqlite3.Error{}
-	if db.Error != nil && errors.As(db.Error, &sqliteErr) && int(sqliteErr.ExtendedCode) == int(sqlite3.ErrConstraintUnique) {
-		return nil, service.ErrFileConflict
-	}
-
 	if db.Error != nil {
 		return nil, db.Error
 	}
 
-	return file, db.Error
+	return model.RemapToServiceFiles(existing...), nil
 }
 
-func (f *File) BatchCreate(ctx context.Context, file ...model.File) error {
-	if len(file) == 0 {
+func (f *File) BatchCreate(ctx context.Context, files ...service.File) error {
+	if len(files) == 0 {
 		return nil
 	}
 
-	db := session.DB(ctx, f.DB).Clauses(clause.OnConflict{DoNothing: true}).Create(file)
-	sqliteErr := sqlite3.Error{}
-	if db.Error != nil && errors.As(db.Error, &sqliteErr) && int(sqliteErr.ExtendedCode) == int(sqlite3.ErrConstraintUnique) {
+	db := session.DB(ctx, f.DB).CreateInBatches(model.RemapFromServiceFiles(files...), batchSize)
+	if db.Error != nil && isUniqueViolation(db.Error) {
 		return service.ErrFileConflict
 	}
 
@@ -105,32 +61,52 @@ func (f *File) BatchCreate(ctx context.Context, file ...model.File) error {
 		return db.Error
 	}
 
-	return db.Error
+	return nil
 }
 
-func (f *File) Update(ctx context.Context, file *model.File) error {
-	existing := &model.File{
-		ID: file.ID,
+func (f *File) DeleteByModuleID(ctx context.Context, id uint) error {
+	db := session.DB(ctx, f.DB).Omit(clause.Associations).Where("module_id = ?", id).Delete(&model.File{})
+	if db.Error != nil {
+		return fmt.Errorf("delete by module id: %w", db.Error)
 	}
 
-	db := session.DB(ctx, f.DB).Omit(clause.Associations).Where("id = ?", file.ID).First(existing)
-	if db.Error != nil {
-		return fmt.Errorf("first select: %w", db.Error)
+	return nil
+}
+
+func (f *File) DeleteByIDs(ctx context.Context, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
 	}
 
-	db = session.DB(ctx, f.DB).Omit(clause.Associations).Model(existing).Updates(file)
+	db := session.DB(ctx, f.DB).Omit(clause.Associations).Where("id IN ?", ids).Delete(&model.File{})
 	if db.Error != nil {
-		return fmt.Errorf("update: %w", db.Error)
+		return fmt.Errorf("delete by ids: %w", db.Error)
 	}
 
 	return nil
 }
 
-func (f *File) DeleteByModuleID(ctx context.Context, id uint) error {
-	db := session.DB(ctx, f.DB).Omit(clause.Associations).Where("module_id = ?", id).Delete(&model.File{})
-	if db.Error != nil {
-		return fmt.Errorf("delete by module id: %w", db.Error)
+func (f *File) UpdateContents(ctx context.Context, files ...service.File) error {
+	for _, file := range files {
+		db := session.DB(ctx, f.DB).Model(&model.File{}).Where("id = ?", file.ID).
+			Updates(map[string]any{"size": file.Size, "etag": file.ETag})
+		if db.Error != nil {
+			return fmt.Errorf("update content: %w", db.Error)
+		}
 	}
 
 	return nil
 }
+
+func (f *File) Stats(ctx context.Context) ([]service.ModuleFileStats, error) {
+	existing := make([]model.ModuleFileStats, 0, 1)
+
+	db := session.DB(ctx, f.DB).Model(&model.File{}).
+		Select("module_id, COUNT(*) AS files, COALESCE(SUM(size), 0) AS bytes").
+		Group("module_id").Order("module_id").Scan(&existing)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceModuleFileStats(existing...), nil
+}
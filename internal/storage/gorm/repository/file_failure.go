@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/session"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	"gorm.io/gorm"
+)
+
+var _ service.FileFailureRepository = (*FileFailure)(nil)
+
+type FileFailure struct {
+	DB *gorm.DB
+}
+
+func NewFileFailure(db *gorm.DB) *FileFailure {
+	return &FileFailure{
+		DB: db,
+	}
+}
+
+func (ff *FileFailure) ReplaceModule(ctx context.Context, moduleID uint, failures ...service.FileFailure) error {
+	db := session.DB(ctx, ff.DB).Where("module_id = ?", moduleID).Delete(&model.FileFailure{})
+	if db.Error != nil {
+		return fmt.Errorf("delete by module id: %w", db.Error)
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	created := model.RemapFromServiceFileFailures(failures...)
+	for i := range created {
+		created[i].ModuleID = moduleID
+	}
+
+	db = session.DB(ctx, ff.DB).CreateInBatches(created, batchSize)
+	if db.Error != nil {
+		return fmt.Errorf("create: %w", db.Error)
+	}
+
+	return nil
+}
+
+func (ff *FileFailure) List(ctx context.Context) ([]service.FileFailure, error) {
+	existing := make([]model.FileFailure, 0, 1)
+
+	db := session.DB(ctx, ff.DB).Order("id").Find(&existing)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceFileFailures(existing...), nil
+}
@@ -4,17 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
 	"github.com/ldmonster/tts-parser/internal/storage/gorm/session"
 
 	service "github.com/ldmonster/tts-parser/internal"
 
-	"github.com/mattn/go-sqlite3"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+var _ service.ModuleRepository = (*Module)(nil)
+
 type Module struct {
 	DB *gorm.DB
 }
@@ -25,61 +28,92 @@ func NewModule(db *gorm.DB) *Module {
 	}
 }
 
-func (m *Module) AutoMigrate(ctx context.Context) error {
-	return session.DB(ctx, m.DB).Omit(clause.Associations).AutoMigrate(&model.Module{})
-}
+func (m *Module) Get(ctx context.Context, id uint) (*service.Module, error) {
+	existing := &model.Module{}
 
-func (m *Module) Get(ctx context.Context, id uint) (*model.Module, error) {
-	existing := &model.Module{
-		ID: id,
+	db := withMetadata(session.DB(ctx, m.DB)).Where("id = ?", id).First(existing)
+	if db.Error != nil && errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		return nil, service.ErrModuleIsNotFound
 	}
 
-	db := session.DB(ctx, m.DB).Omit(clause.Associations).First(existing)
+	if db.Error != nil {
+		return nil, db.Error
+	}
 
-	return existing, db.Error
+	return model.RemapToServiceModule(existing), nil
 }
 
-func (m *Module) GetByTelegramID(ctx context.Context, telegramID int64) (*model.Module, error) {
-	existing := &model.Module{}
+func (m *Module) List(ctx context.Context) ([]service.Module, error) {
+	existing := make([]model.Module, 0, 1)
 
-	db := session.DB(ctx, m.DB).Omit(clause.Associations).Where("telegram_id = ?", telegramID).First(existing)
-	if db.Error != nil && errors.Is(db.Error, gorm.ErrRecordNotFound) {
-		return nil, service.ErrModuleIsNotFound
+	db := withMetadata(session.DB(ctx, m.DB)).Order("id").Find(&existing)
+	if db.Error != nil {
+		return nil, db.Error
 	}
 
+	return model.RemapToServiceModules(existing...), nil
+}
+
+func (m *Module) ListByStatus(ctx context.Context, status service.ModuleStatus) ([]service.Module, error) {
+	existing := make([]model.Module, 0, 1)
+
+	db := withMetadata(session.DB(ctx, m.DB)).Where("status = ?", status).Order("id").Find(&existing)
 	if db.Error != nil {
 		return nil, db.Error
 	}
 
-	return existing, nil
+	return model.RemapToServiceModules(existing...), nil
 }
 
-func (m *Module) List(ctx context.Context) ([]model.Module, error) {
+func (m *Module) Find(ctx context.Context, filter service.ModuleFilter) ([]service.Module, error) {
 	existing := make([]model.Module, 0, 1)
 
-	db := session.DB(ctx, m.DB).Omit(clause.Associations).Find(&existing)
+	db := withMetadata(session.DB(ctx, m.DB))
 
-	return existing, db.Error
-}
+	if len(filter.Statuses) > 0 {
+		db = db.Where("status IN ?", filter.Statuses)
+	}
+
+	if filter.Players > 0 {
+		db = db.Where("id IN (SELECT module_id FROM module_player_counts WHERE players = ?)", filter.Players)
+	}
+
+	for _, tag := range filter.Tags {
+		db = db.Where("id IN (SELECT module_id FROM module_tags WHERE LOWER(tag) = LOWER(?))", tag)
+	}
+
+	if filter.MaxPlayingTime > 0 {
+		db = db.Where("max_playing_time BETWEEN 1 AND ?", filter.MaxPlayingTime)
+	}
+
+	if filter.GameType != "" {
+		db = db.Where("LOWER(game_type) = LOWER(?)", filter.GameType)
+	}
 
-func (m *Module) Create(ctx context.Context, module *model.Module) (*model.Module, error) {
-	db := session.DB(ctx, m.DB).Omit(clause.Associations).Create(module)
-	sqliteErr := sqlite3.Error{}
-	if db.Error != nil && errors.As(db.Error, &sqliteErr) && int(sqliteErr.ExtendedCode) == int(sqlite3.ErrConstraintUnique) {
-		return nil, service.ErrModuleConflict
+	if filter.GameComplexity != "" {
+		db = db.Where("LOWER(game_complexity) = LOWER(?)", filter.GameComplexity)
 	}
 
+	db = db.Order("id").Find(&existing)
 	if db.Error != nil {
 		return nil, db.Error
 	}
 
-	return module, db.Error
+	return model.RemapToServiceModules(existing...), nil
+}
+
+// withMetadata loads tags and player counts along with modules.
+func withMetadata(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tag") }).
+		Preload("PlayerCounts", func(db *gorm.DB) *gorm.DB { return db.Order("players") })
 }
 
-func (m *Module) BatchCreate(ctx context.Context, module ...model.Module) error {
-	db := session.DB(ctx, m.DB).Clauses(clause.OnConflict{DoNothing: true}).Create(module)
-	sqliteErr := sqlite3.Error{}
-	if db.Error != nil && errors.As(db.Error, &sqliteErr) && int(sqliteErr.ExtendedCode) == int(sqlite3.ErrConstraintUnique) {
+func (m *Module) Create(ctx context.Context, module *service.Module) error {
+	created := model.RemapFromServiceModule(module)
+
+	db := session.DB(ctx, m.DB).Omit(clause.Associations).Create(created)
+	if db.Error != nil && isUniqueViolation(db.Error) {
 		return service.ErrModuleConflict
 	}
 
@@ -87,23 +121,115 @@ func (m *Module) BatchCreate(ctx context.Context, module ...model.Module) error
 		return db.Error
 	}
 
-	return db.Error
+	module.ID = created.ID
+
+	return m.createAssociations(ctx, module)
+}
+
+// Upsert creates the module or updates every column of the existing one.
+func (m *Module) Upsert(ctx context.Context, module *service.Module) error {
+	db := session.DB(ctx, m.DB).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		UpdateAll: true,
+	}).Create(model.RemapFromServiceModule(module))
+	if db.Error != nil {
+		return fmt.Errorf("upsert: %w", db.Error)
+	}
+
+	db = session.DB(ctx, m.DB).Where("module_id = ?", module.ID).Delete(&model.ModuleTag{})
+	if db.Error != nil {
+		return fmt.Errorf("delete tags: %w", db.Error)
+	}
+
+	db = session.DB(ctx, m.DB).Where("module_id = ?", module.ID).Delete(&model.ModulePlayerCount{})
+	if db.Error != nil {
+		return fmt.Errorf("delete player counts: %w", db.Error)
+	}
+
+	return m.createAssociations(ctx, module)
 }
 
-func (m *Module) Update(ctx context.Context, module *model.Module) error {
-	existing := &model.Module{
-		ID: module.ID,
+// createAssociations stores tags and player counts of the module.
+func (m *Module) createAssociations(ctx context.Context, module *service.Module) error {
+	if tags := model.RemapFromServiceModuleTags(module.ID, module.Tags...); len(tags) > 0 {
+		db := session.DB(ctx, m.DB).Clauses(clause.OnConflict{DoNothing: true}).Create(tags)
+		if db.Error != nil {
+			return fmt.Errorf("create tags: %w", db.Error)
+		}
+	}
+
+	if counts := model.RemapFromServiceModulePlayerCounts(module.ID, module.PlayerCounts...); len(counts) > 0 {
+		db := session.DB(ctx, m.DB).Clauses(clause.OnConflict{DoNothing: true}).Create(counts)
+		if db.Error != nil {
+			return fmt.Errorf("create player counts: %w", db.Error)
+		}
 	}
 
+	return nil
+}
+
+func (m *Module) Update(ctx context.Context, module *service.Module) error {
+	existing := &model.Module{}
+
 	db := session.DB(ctx, m.DB).Omit(clause.Associations).Where("id = ?", module.ID).First(existing)
+	if db.Error != nil && errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		return service.ErrModuleIsNotFound
+	}
+
 	if db.Error != nil {
 		return fmt.Errorf("first select: %w", db.Error)
 	}
 
-	db = session.DB(ctx, m.DB).Omit(clause.Associations).Model(existing).Updates(module)
+	db = session.DB(ctx, m.DB).Omit(clause.Associations).Model(existing).Updates(model.RemapFromServiceModule(module))
+	if db.Error != nil {
+		return fmt.Errorf("update: %w", db.Error)
+	}
+
+	return nil
+}
+
+// MarkSeen makes the modules active and sets the time their workshop file was last seen.
+func (m *Module) MarkSeen(ctx context.Context, at time.Time, ids ...uint) error {
+	for batch := range slices.Chunk(ids, batchSize) {
+		db := session.DB(ctx, m.DB).Model(&model.Module{}).Where("id IN ?", batch).Updates(map[string]any{
+			"status":       service.ModuleStatusActive,
+			"last_seen_at": at,
+			"removed_at":   time.Time{},
+		})
+		if db.Error != nil {
+			return fmt.Errorf("update: %w", db.Error)
+		}
+	}
+
+	return nil
+}
+
+// MarkRemoved sets the removed status on active modules among ids.
+func (m *Module) MarkRemoved(ctx context.Context, at time.Time, ids ...uint) error {
+	for batch := range slices.Chunk(ids, batchSize) {
+		db := session.DB(ctx, m.DB).Model(&model.Module{}).
+			Where("id IN ? AND status = ?", batch, service.ModuleStatusActive).
+			Updates(map[string]any{
+				"status":     service.ModuleStatusRemoved,
+				"removed_at": at,
+			})
+		if db.Error != nil {
+			return fmt.Errorf("update: %w", db.Error)
+		}
+	}
+
+	return nil
+}
+
+func (m *Module) UpdateStatus(ctx context.Context, id uint, status service.ModuleStatus) error {
+	db := session.DB(ctx, m.DB).Model(&model.Module{}).Where("id = ?", id).Update("status", status)
 	if db.Error != nil {
 		return fmt.Errorf("update: %w", db.Error)
 	}
 
+	if db.RowsAffected == 0 {
+		return service.ErrModuleIsNotFound
+	}
+
 	return nil
 }
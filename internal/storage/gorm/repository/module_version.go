@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/session"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ service.ModuleVersionRepository = (*ModuleVersion)(nil)
+
+type ModuleVersion struct {
+	DB *gorm.DB
+}
+
+func NewModuleVersion(db *gorm.DB) *ModuleVersion {
+	return &ModuleVersion{
+		DB: db,
+	}
+}
+
+// Get returns the version including its data.
+func (mv *ModuleVersion) Get(ctx context.Context, id uint) (*service.ModuleVersion, error) {
+	existing := &model.ModuleVersion{}
+
+	db := session.DB(ctx, mv.DB).Omit(clause.Associations).Where("id = ?", id).First(existing)
+	if db.Error != nil && errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		return nil, service.ErrModuleVersionIsNotFound
+	}
+
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceModuleVersion(existing), nil
+}
+
+// ListByModuleID returns versions of the module oldest first, without data.
+func (mv *ModuleVersion) ListByModuleID(ctx context.Context, moduleID uint) ([]service.ModuleVersion, error) {
+	existing := make([]model.ModuleVersion, 0, 1)
+
+	db := session.DB(ctx, mv.DB).Omit(clause.Associations, "data").Where("module_id = ?", moduleID).Order("id").Find(&existing)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceModuleVersions(existing...), nil
+}
+
+// Create stores the version unless the module already has one with the same hash.
+func (mv *ModuleVersion) Create(ctx context.Context, version *service.ModuleVersion) error {
+	created := model.RemapFromServiceModuleVersion(version)
+
+	db := session.DB(ctx, mv.DB).Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(created)
+	if db.Error != nil {
+		return fmt.Errorf("create: %w", db.Error)
+	}
+
+	version.ID = created.ID
+	version.CreatedAt = created.CreatedAt
+
+	return nil
+}
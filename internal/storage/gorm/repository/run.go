@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/session"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	"gorm.io/gorm"
+)
+
+var _ service.RunRepository = (*Run)(nil)
+
+type Run struct {
+	DB *gorm.DB
+}
+
+func NewRun(db *gorm.DB) *Run {
+	return &Run{
+		DB: db,
+	}
+}
+
+func (r *Run) Create(ctx context.Context, run *service.Run) error {
+	created := model.RemapFromServiceRun(run)
+
+	db := session.DB(ctx, r.DB).Create(created)
+	if db.Error != nil {
+		return db.Error
+	}
+
+	run.ID = created.ID
+
+	return nil
+}
+
+func (r *Run) Update(ctx context.Context, run *service.Run) error {
+	db := session.DB(ctx, r.DB).Select("*").Where("id = ?", run.ID).Updates(model.RemapFromServiceRun(run))
+	if db.Error != nil {
+		return fmt.Errorf("update: %w", db.Error)
+	}
+
+	if db.RowsAffected == 0 {
+		return service.ErrRunIsNotFound
+	}
+
+	return nil
+}
+
+func (r *Run) Get(ctx context.Context, id uint) (*service.Run, error) {
+	existing := &model.Run{}
+
+	db := session.DB(ctx, r.DB).Where("id = ?", id).First(existing)
+	if db.Error != nil && errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		return nil, service.ErrRunIsNotFound
+	}
+
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceRun(existing), nil
+}
+
+func (r *Run) List(ctx context.Context, limit int) ([]service.Run, error) {
+	existing := make([]model.Run, 0, 1)
+
+	db := session.DB(ctx, r.DB).Order("id DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	db = db.Find(&existing)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceRuns(existing...), nil
+}
+
+func (r *Run) AddModules(ctx context.Context, modules ...service.RunModule) error {
+	if len(modules) == 0 {
+		return nil
+	}
+
+	db := session.DB(ctx, r.DB).CreateInBatches(model.RemapFromServiceRunModules(modules...), batchSize)
+	if db.Error != nil {
+		return fmt.Errorf("create: %w", db.Error)
+	}
+
+	return nil
+}
+
+func (r *Run) ListModules(ctx context.Context, runID uint) ([]service.RunModule, error) {
+	existing := make([]model.RunModule, 0, 1)
+
+	db := session.DB(ctx, r.DB).Where("run_id = ?", runID).Order("id").Find(&existing)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceRunModules(existing...), nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/session"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// batchSize keeps inserts under the SQLite bound parameters limit.
+const batchSize = 500
+
+var _ service.ScriptRepository = (*Script)(nil)
+
+type Script struct {
+	DB *gorm.DB
+}
+
+func NewScript(db *gorm.DB) *Script {
+	return &Script{
+		DB: db,
+	}
+}
+
+// Upsert stores scripts missing by hash and fills in IDs of all of them.
+func (s *Script) Upsert(ctx context.Context, scripts ...*service.Script) error {
+	if len(scripts) == 0 {
+		return nil
+	}
+
+	created := make([]*model.Script, 0, len(scripts))
+	for _, sc := range scripts {
+		created = append(created, model.RemapFromServiceScript(sc))
+	}
+
+	db := session.DB(ctx, s.DB).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(created, batchSize)
+	if db.Error != nil {
+		return fmt.Errorf("create: %w", db.Error)
+	}
+
+	hashes := make([]string, 0, len(scripts))
+	for _, sc := range scripts {
+		hashes = append(hashes, sc.Hash)
+	}
+
+	ids := make([]model.Script, 0, len(scripts))
+
+	db = session.DB(ctx, s.DB).Select("id", "hash").Where("hash IN ?", hashes).Find(&ids)
+	if db.Error != nil {
+		return fmt.Errorf("select ids: %w", db.Error)
+	}
+
+	byHash := make(map[string]uint, len(ids))
+	for _, sc := range ids {
+		byHash[sc.Hash] = sc.ID
+	}
+
+	for _, sc := range scripts {
+		sc.ID = byHash[sc.Hash]
+	}
+
+	return nil
+}
+
+func (s *Script) BatchCreateIncludes(ctx context.Context, includes ...service.ScriptInclude) error {
+	if len(includes) == 0 {
+		return nil
+	}
+
+	db := session.DB(ctx, s.DB).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(model.RemapFromServiceScriptIncludes(includes...), batchSize)
+
+	return db.Error
+}
+
+// ReplaceUsages drops usages of the module and stores the given ones.
+func (s *Script) ReplaceUsages(ctx context.Context, moduleID uint, usages ...service.ScriptUsage) error {
+	db := session.DB(ctx, s.DB).Where("module_id = ?", moduleID).Delete(&model.ScriptUsage{})
+	if db.Error != nil {
+		return fmt.Errorf("delete by module id: %w", db.Error)
+	}
+
+	if len(usages) == 0 {
+		return nil
+	}
+
+	db = session.DB(ctx, s.DB).CreateInBatches(model.RemapFromServiceScriptUsages(usages...), batchSize)
+	if db.Error != nil {
+		return fmt.Errorf("create: %w", db.Error)
+	}
+
+	return nil
+}
+
+// Search returns usages of scripts whose content contains text, including
+// scripts reached through bundles only.
+func (s *Script) Search(ctx context.Context, text string) ([]service.ScriptMatch, error) {
+	existing := make([]model.ScriptMatch, 0, 1)
+
+	pattern := "%" + likeEscaper.Replace(text) + "%"
+
+	db := session.DB(ctx, s.DB).
+		Table("script_usages").
+		Select("script_usages.*, scripts.hash, scripts.size").
+		Joins("JOIN scripts ON scripts.id = script_usages.script_id").
+		Where(`scripts.content LIKE ? ESCAPE '\' OR script_usages.script_id IN (
+			SELECT script_includes.bundle_id FROM script_includes
+			JOIN scripts included ON included.id = script_includes.script_id
+			WHERE included.content LIKE ? ESCAPE '\')`, pattern, pattern).
+		Order("script_usages.module_id, script_usages.path").
+		Find(&existing)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceScriptMatches(existing...), nil
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/session"
+
+	service "github.com/ldmonster/tts-parser/internal"
+
+	uberzap "go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var _ service.SearchRepository = (*Search)(nil)
+
+type searchMode int
+
+const (
+	// searchModeLike matches words with LIKE, SQLite is built without FTS5 by default.
+	searchModeLike searchMode = iota
+	// searchModeFTS5 keeps an FTS5 index of the documents in search_fts.
+	searchModeFTS5
+	// searchModeTSVector queries the tsv column PostgreSQL generates for documents.
+	searchModeTSVector
+)
+
+// Search indexes module text for full-text search. The FTS5 index is created
+// on first use when the SQLite driver is built with the sqlite_fts5 tag,
+// without it a warning is logged and words are matched with LIKE.
+type Search struct {
+	DB *gorm.DB
+
+	modeOnce sync.Once
+	mode     searchMode
+
+	logger *uberzap.Logger
+}
+
+func NewSearch(db *gorm.DB, l *uberzap.Logger) *Search {
+	return &Search{
+		DB:     db,
+		logger: l,
+	}
+}
+
+func (s *Search) searchMode(ctx context.Context) searchMode {
+	s.modeOnce.Do(func() {
+		switch s.DB.Dialector.Name() {
+		case "postgres":
+			s.mode = searchModeTSVector
+		case "sqlite":
+			var fts5 bool
+
+			db := s.DB.WithContext(ctx).Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5)
+			if db.Error == nil && fts5 {
+				s.mode = searchModeFTS5
+				return
+			}
+
+			s.logger.Debug("SQLite is built without FTS5, search matches words with LIKE instead of a full-text index, "+
+				"build with -tags sqlite_fts5 to enable it", uberzap.Error(db.Error))
+		}
+	})
+
+	return s.mode
+}
+
+// ensureFTS creates the FTS5 index and rebuilds it when documents were changed
+// without it, e.g. by a binary built without FTS5. Document ids are never
+// reused, so any change shows in the count or the last id.
+func (s *Search) ensureFTS(ctx context.Context) error {
+	db := session.DB(ctx, s.DB)
+
+	err := db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS search_fts USING fts5(title, body)").Error
+	if err != nil {
+		return fmt.Errorf("create fts index: %w", err)
+	}
+
+	var synced bool
+
+	err = db.Raw(`SELECT (SELECT COUNT(*) FROM search_documents) = (SELECT COUNT(*) FROM search_fts)
+		AND (SELECT COALESCE(MAX(id), 0) FROM search_documents) = (SELECT COALESCE(MAX(rowid), 0) FROM search_fts)`).Scan(&synced).Error
+	if err != nil {
+		return fmt.Errorf("check fts index: %w", err)
+	}
+
+	if synced {
+		return nil
+	}
+
+	err = db.Exec("DELETE FROM search_fts").Error
+	if err != nil {
+		return fmt.Errorf("clear fts index: %w", err)
+	}
+
+	err = db.Exec("INSERT INTO search_fts (rowid, title, body) SELECT id, title, body FROM search_documents").Error
+	if err != nil {
+		return fmt.Errorf("rebuild fts index: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceModule drops documents of the module and indexes the given ones.
+func (s *Search) ReplaceModule(ctx context.Context, moduleID uint, docs ...service.SearchDocument) error {
+	fts := s.searchMode(ctx) == searchModeFTS5
+
+	if fts {
+		err := s.ensureFTS(ctx)
+		if err != nil {
+			return err
+		}
+
+		db := session.DB(ctx, s.DB).Exec("DELETE FROM search_fts WHERE rowid IN (SELECT id FROM search_documents WHERE module_id = ?)", moduleID)
+		if db.Error != nil {
+			return fmt.Errorf("delete from fts index: %w", db.Error)
+		}
+	}
+
+	db := session.DB(ctx, s.DB).Where("module_id = ?", moduleID).Delete(&model.SearchDocument{})
+	if db.Error != nil {
+		return fmt.Errorf("delete by module id: %w", db.Error)
+	}
+
+	if len(docs) == 0 {
+		return nil
+	}
+
+	created := model.RemapFromServiceSearchDocuments(docs...)
+	for i := range created {
+		created[i].ModuleID = moduleID
+	}
+
+	db = session.DB(ctx, s.DB).CreateInBatches(created, batchSize)
+	if db.Error != nil {
+		return fmt.Errorf("create: %w", db.Error)
+	}
+
+	if fts {
+		db = session.DB(ctx, s.DB).Exec("INSERT INTO search_fts (rowid, title, body) SELECT id, title, body FROM search_documents WHERE module_id = ?", moduleID)
+		if db.Error != nil {
+			return fmt.Errorf("add to fts index: %w", db.Error)
+		}
+	}
+
+	return nil
+}
+
+// Search matches titles ten times higher than bodies.
+func (s *Search) Search(ctx context.Context, query string, limit int) ([]service.SearchHit, error) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	existing := make([]model.SearchHit, 0, 1)
+
+	var db *gorm.DB
+
+	switch s.searchMode(ctx) {
+	case searchModeFTS5:
+		err := s.ensureFTS(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		db = session.DB(ctx, s.DB).Raw(`SELECT search_documents.*, -bm25(search_fts, 10.0, 1.0) AS rank
+			FROM search_fts JOIN search_documents ON search_documents.id = search_fts.rowid
+			WHERE search_fts MATCH ? ORDER BY rank DESC LIMIT ?`, ftsQuery(words), limit)
+	case searchModeTSVector:
+		db = session.DB(ctx, s.DB).Raw(`SELECT search_documents.*, ts_rank(tsv, plainto_tsquery('simple', ?)) AS rank
+			FROM search_documents WHERE tsv @@ plainto_tsquery('simple', ?) ORDER BY rank DESC LIMIT ?`, query, query, limit)
+	default:
+		db = likeQuery(session.DB(ctx, s.DB), words, limit)
+	}
+
+	db = db.Scan(&existing)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	return model.RemapToServiceSearchHits(existing...), nil
+}
+
+// ftsQuery matches documents containing every word as a prefix, words are
+// quoted so FTS5 syntax in the query is taken literally.
+func ftsQuery(words []string) string {
+	terms := make([]string, 0, len(words))
+
+	for _, w := range words {
+		terms = append(terms, `"`+strings.ReplaceAll(w, `"`, `""`)+`"*`)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+func likeQuery(db *gorm.DB, words []string, limit int) *gorm.DB {
+	var (
+		rank  []string
+		where []string
+		args  []any
+	)
+
+	for _, w := range words {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(w)) + "%"
+
+		rank = append(rank, `CASE WHEN LOWER(title) LIKE ? ESCAPE '\' THEN 10 ELSE 0 END + CASE WHEN LOWER(body) LIKE ? ESCAPE '\' THEN 1 ELSE 0 END`)
+		where = append(where, `(LOWER(title) LIKE ? ESCAPE '\' OR LOWER(body) LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+
+	// rank placeholders come first in the statement
+	args = append(args, args...)
+	args = append(args, limit)
+
+	return db.Raw("SELECT search_documents.*, "+strings.Join(rank, " + ")+" AS rank FROM search_documents WHERE "+
+		strings.Join(where, " AND ")+" ORDER BY rank DESC, id LIMIT ?", args...)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
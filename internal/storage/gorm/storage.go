@@ -8,12 +8,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/ldmonster/tts-parser/internal/storage/gorm/migration"
 	"github.com/ldmonster/tts-parser/internal/storage/gorm/repository"
 	"github.com/ldmonster/tts-parser/internal/storage/gorm/session"
 
-	"github.com/ldmonster/tts-parser/internal/storage/gorm/model"
-
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite" // Sqlite driver based on CGO
 	"gorm.io/gorm/logger"
 
@@ -24,15 +25,35 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	_ service.Storage  = (*Storage)(nil)
+	_ service.Migrator = (*Storage)(nil)
+)
+
 type Storage struct {
-	gorm   session.Gorm
-	Module *repository.Module
-	File   *repository.File
+	db       *gorm.DB
+	gorm     session.Gorm
+	migrator *migration.Migrator
+
+	// sqlitePath is the database file, empty for other drivers.
+	sqlitePath string
+	// sqliteCreated is set when the database file did not exist before.
+	sqliteCreated bool
+
+	Module         *repository.Module
+	File           *repository.File
+	FileFailure    *repository.FileFailure
+	Script         *repository.Script
+	SearchDocument *repository.Search
+	Run            *repository.Run
+
+	ModuleVersion *repository.ModuleVersion
 
 	logger *uberzap.Logger
 }
 
-func NewStorage(dbpath string, l *uberzap.Logger) (*Storage, error) {
+// NewSQLiteStorage opens the SQLite database file, creating its directory.
+func NewSQLiteStorage(dbpath string, l *uberzap.Logger) (*Storage, error) {
 	dir := filepath.Dir(dbpath)
 
 	err := os.MkdirAll(dir, 0777)
@@ -40,81 +61,160 @@ func NewStorage(dbpath string, l *uberzap.Logger) (*Storage, error) {
 		return nil, fmt.Errorf("mkdir to db: %w", err)
 	}
 
-	gcfg := &gorm.Config{}
+	info, err := os.Stat(dbpath)
+	created := err != nil || info.Size() == 0
+
+	s, err := newStorage(sqlite.Open(dbpath), l)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sqlitePath = dbpath
+	s.sqliteCreated = created
+
+	return s, nil
+}
+
+// NewPostgresStorage connects to PostgreSQL, dsn is either a URL or key=value pairs.
+func NewPostgresStorage(dsn string, l *uberzap.Logger) (*Storage, error) {
+	return newStorage(postgres.Open(dsn), l)
+}
+
+func newStorage(dialector gorm.Dialector, l *uberzap.Logger) (*Storage, error) {
+	// driver errors are translated to gorm ones, e.g. unique violations to gorm.ErrDuplicatedKey
+	gcfg := &gorm.Config{
+		TranslateError: true,
+	}
 
 	if l.Level() == uberzap.DebugLevel {
 		gcfg.Logger = logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{LogLevel: logger.Info})
 	}
 
-	db, err := gorm.Open(sqlite.Open(dbpath), gcfg)
+	db, err := gorm.Open(dialector, gcfg)
 	if err != nil {
 		return nil, fmt.Errorf("open db connection: %w", err)
 	}
 
 	return &Storage{
-		gorm:   session.GORM(db, &sql.TxOptions{}),
-		Module: repository.NewModule(db),
-		File:   repository.NewFile(db),
+		db:       db,
+		gorm:     session.GORM(db, &sql.TxOptions{}),
+		migrator: migration.New(db),
+
+		Module:         repository.NewModule(db),
+		File:           repository.NewFile(db),
+		FileFailure:    repository.NewFileFailure(db),
+		Script:         repository.NewScript(db),
+		SearchDocument: repository.NewSearch(db, l.Named("search")),
+		Run:            repository.NewRun(db),
+
+		ModuleVersion: repository.NewModuleVersion(db),
+
 		logger: l,
 	}, nil
 }
 
-func (s *Storage) AutoMigrate(ctx context.Context) error {
-	session, err := s.gorm.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer session.Rollback()
+func (s *Storage) Modules() service.ModuleRepository {
+	return s.Module
+}
+
+func (s *Storage) Files() service.FileRepository {
+	return s.File
+}
+
+func (s *Storage) FileFailures() service.FileFailureRepository {
+	return s.FileFailure
+}
+
+func (s *Storage) ModuleVersions() service.ModuleVersionRepository {
+	return s.ModuleVersion
+}
+
+func (s *Storage) Scripts() service.ScriptRepository {
+	return s.Script
+}
+
+func (s *Storage) Search() service.SearchRepository {
+	return s.SearchDocument
+}
+
+func (s *Storage) Runs() service.RunRepository {
+	return s.Run
+}
+
+// Migrate applies pending migrations.
+func (s *Storage) Migrate(ctx context.Context) error {
+	_, err := s.MigrateUp(ctx, 0)
+
+	return err
+}
+
+func (s *Storage) MigrationStatus(ctx context.Context) ([]service.MigrationStatus, error) {
+	return s.migrator.Status(ctx)
+}
 
-	err = s.Module.AutoMigrate(ctx)
+// MigrateUp backs up the SQLite database before applying pending migrations.
+func (s *Storage) MigrateUp(ctx context.Context, steps int) (int, error) {
+	pending, err := s.migrator.Pending(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	err = s.File.AutoMigrate(ctx)
-	if err != nil {
-		return err
+	if pending == 0 {
+		return 0, nil
 	}
 
-	err = session.Commit()
+	err = s.backup(ctx)
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("backup: %w", err)
 	}
 
-	return nil
-}
-
-func (s *Storage) StartTransaction(ctx context.Context) (session.Session, error) {
-	return s.gorm.Begin(ctx)
+	return s.migrator.Up(ctx, steps)
 }
 
-func (s *Storage) Register(ctx context.Context, telegramID int64, modulename string, lastMessageID int, startingBalance map[string]int64) error {
-	newModule := &model.Module{}
-
-	newModule, cErr := s.Module.Create(ctx, newModule)
-	if cErr != nil && errors.Is(cErr, service.ErrModuleConflict) {
-		s.logger.Error("creating module", uberzap.Any("module", newModule), uberzap.Error(cErr))
-		return fmt.Errorf("creating module: %w", cErr)
+// MigrateDown backs up the SQLite database before reverting migrations.
+func (s *Storage) MigrateDown(ctx context.Context, steps int) (int, error) {
+	err := s.backup(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("backup: %w", err)
 	}
 
-	return nil
+	return s.migrator.Down(ctx, steps)
 }
 
-func (s *Storage) UpdateModule(ctx context.Context, telegramID int64, fn func(u *model.Module) error) error {
-	module, err := s.Module.GetByTelegramID(ctx, telegramID)
-	if err != nil {
-		return fmt.Errorf("get by telegram id: %w", err)
+// backup copies the SQLite database next to it, new databases are skipped.
+func (s *Storage) backup(ctx context.Context) error {
+	if s.sqlitePath == "" || s.sqliteCreated {
+		return nil
 	}
 
-	err = fn(module)
-	if err != nil {
-		return fmt.Errorf("mutating func: %w", err)
+	stamp := time.Now().Format("20060102-150405")
+	path := fmt.Sprintf("%s.%s.bak", s.sqlitePath, stamp)
+
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+
+		path = fmt.Sprintf("%s.%s-%d.bak", s.sqlitePath, stamp, n)
 	}
 
-	err = s.Module.Update(ctx, module)
+	// VACUUM INTO writes a consistent copy, unlike copying the file with a journal around
+	err := s.db.WithContext(ctx).Exec("VACUUM INTO ?", path).Error
 	if err != nil {
-		return fmt.Errorf("update: %w", err)
+		return err
 	}
 
+	s.logger.Info("database backed up", uberzap.String("path", path))
+
 	return nil
 }
+
+func (s *Storage) StartTransaction(ctx context.Context) (session.Session, error) {
+	return s.gorm.Begin(ctx)
+}
+
+// Transaction runs f in a single DB transaction, repositories called with the
+// context passed to f take part in it.
+func (s *Storage) Transaction(ctx context.Context, f func(context.Context) error) error {
+	return s.gorm.Transaction(ctx, f)
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+var _ service.FileRepository = (*File)(nil)
+
+type File struct {
+	s *Storage
+}
+
+func (f *File) List(ctx context.Context) ([]service.File, error) {
+	defer f.s.lock(ctx)()
+
+	return sortedValues(f.s.data.files), nil
+}
+
+func (f *File) ListByModuleID(ctx context.Context, id uint) ([]service.File, error) {
+	defer f.s.lock(ctx)()
+
+	result := make([]service.File, 0, 1)
+
+	for _, file := range sortedValues(f.s.data.files) {
+		if file.ModuleID == id {
+			result = append(result, file)
+		}
+	}
+
+	return result, nil
+}
+
+// BatchCreate fails with ErrFileConflict when the module has stored a URL already.
+func (f *File) BatchCreate(ctx context.Context, files ...service.File) error {
+	defer f.s.lock(ctx)()
+
+	type key struct {
+		moduleID uint
+		url      string
+	}
+
+	stored := make(map[key]struct{}, len(f.s.data.files))
+	for _, file := range f.s.data.files {
+		stored[key{file.ModuleID, file.URL}] = struct{}{}
+	}
+
+	for _, file := range files {
+		if _, ok := stored[key{file.ModuleID, file.URL}]; ok {
+			return service.ErrFileConflict
+		}
+
+		stored[key{file.ModuleID, file.URL}] = struct{}{}
+	}
+
+	for _, file := range files {
+		file.ID = f.s.data.nextID("files")
+		if file.CreatedAt.IsZero() {
+			file.CreatedAt = time.Now()
+		}
+
+		put(f.s, f.s.data.files, file.ID, file)
+	}
+
+	return nil
+}
+
+func (f *File) DeleteByIDs(ctx context.Context, ids ...uint) error {
+	defer f.s.lock(ctx)()
+
+	for _, id := range ids {
+		remove(f.s, f.s.data.files, id)
+	}
+
+	return nil
+}
+
+func (f *File) DeleteByModuleID(ctx context.Context, id uint) error {
+	defer f.s.lock(ctx)()
+
+	for fid, file := range f.s.data.files {
+		if file.ModuleID == id {
+			remove(f.s, f.s.data.files, fid)
+		}
+	}
+
+	return nil
+}
+
+func (f *File) UpdateContents(ctx context.Context, files ...service.File) error {
+	defer f.s.lock(ctx)()
+
+	for _, file := range files {
+		existing, ok := f.s.data.files[file.ID]
+		if !ok {
+			continue
+		}
+
+		existing.Size = file.Size
+		existing.ETag = file.ETag
+		put(f.s, f.s.data.files, file.ID, existing)
+	}
+
+	return nil
+}
+
+func (f *File) Stats(ctx context.Context) ([]service.ModuleFileStats, error) {
+	defer f.s.lock(ctx)()
+
+	byModule := make(map[uint]service.ModuleFileStats)
+
+	for _, file := range f.s.data.files {
+		stats := byModule[file.ModuleID]
+		stats.ModuleID = file.ModuleID
+		stats.Files++
+		stats.Bytes += file.Size
+		byModule[file.ModuleID] = stats
+	}
+
+	return sortedValues(byModule), nil
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+var _ service.FileFailureRepository = (*FileFailure)(nil)
+
+type FileFailure struct {
+	s *Storage
+}
+
+func (ff *FileFailure) ReplaceModule(ctx context.Context, moduleID uint, failures ...service.FileFailure) error {
+	defer ff.s.lock(ctx)()
+
+	for id, existing := range ff.s.data.failures {
+		if existing.ModuleID == moduleID {
+			remove(ff.s, ff.s.data.failures, id)
+		}
+	}
+
+	for _, failure := range failures {
+		failure.ID = ff.s.data.nextID("file_failures")
+		failure.ModuleID = moduleID
+		put(ff.s, ff.s.data.failures, failure.ID, failure)
+	}
+
+	return nil
+}
+
+func (ff *FileFailure) List(ctx context.Context) ([]service.FileFailure, error) {
+	defer ff.s.lock(ctx)()
+
+	return sortedValues(ff.s.data.failures), nil
+}
@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+var _ service.ModuleRepository = (*Module)(nil)
+
+type Module struct {
+	s *Storage
+}
+
+func (m *Module) Get(ctx context.Context, id uint) (*service.Module, error) {
+	defer m.s.lock(ctx)()
+
+	existing, ok := m.s.data.modules[id]
+	if !ok {
+		return nil, service.ErrModuleIsNotFound
+	}
+
+	return &existing, nil
+}
+
+func (m *Module) List(ctx context.Context) ([]service.Module, error) {
+	defer m.s.lock(ctx)()
+
+	return sortedValues(m.s.data.modules), nil
+}
+
+func (m *Module) ListByStatus(ctx context.Context, status service.ModuleStatus) ([]service.Module, error) {
+	defer m.s.lock(ctx)()
+
+	result := make([]service.Module, 0, 1)
+
+	for _, mod := range sortedValues(m.s.data.modules) {
+		if mod.Status == status {
+			result = append(result, mod)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *Module) Find(ctx context.Context, filter service.ModuleFilter) ([]service.Module, error) {
+	defer m.s.lock(ctx)()
+
+	result := make([]service.Module, 0, 1)
+
+	for _, mod := range sortedValues(m.s.data.modules) {
+		if matchModule(mod, filter) {
+			result = append(result, mod)
+		}
+	}
+
+	return result, nil
+}
+
+func matchModule(mod service.Module, filter service.ModuleFilter) bool {
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, mod.Status) {
+		return false
+	}
+
+	if filter.Players > 0 && !slices.Contains(mod.PlayerCounts, filter.Players) {
+		return false
+	}
+
+	for _, tag := range filter.Tags {
+		if !slices.ContainsFunc(mod.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return false
+		}
+	}
+
+	if filter.MaxPlayingTime > 0 && (mod.MaxPlayingTime < 1 || mod.MaxPlayingTime > filter.MaxPlayingTime) {
+		return false
+	}
+
+	if filter.GameType != "" && !strings.EqualFold(mod.GameType, filter.GameType) {
+		return false
+	}
+
+	if filter.GameComplexity != "" && !strings.EqualFold(mod.GameComplexity, filter.GameComplexity) {
+		return false
+	}
+
+	return true
+}
+
+func (m *Module) Create(ctx context.Context, module *service.Module) error {
+	defer m.s.lock(ctx)()
+
+	if _, ok := m.s.data.modules[module.ID]; ok {
+		return service.ErrModuleConflict
+	}
+
+	if module.ID == 0 {
+		module.ID = m.s.data.nextID("modules")
+	}
+
+	put(m.s, m.s.data.modules, module.ID, withDefaultStatus(*module))
+
+	return nil
+}
+
+func (m *Module) Upsert(ctx context.Context, module *service.Module) error {
+	defer m.s.lock(ctx)()
+
+	put(m.s, m.s.data.modules, module.ID, withDefaultStatus(*module))
+
+	return nil
+}
+
+func (m *Module) Update(ctx context.Context, module *service.Module) error {
+	defer m.s.lock(ctx)()
+
+	existing, ok := m.s.data.modules[module.ID]
+	if !ok {
+		return service.ErrModuleIsNotFound
+	}
+
+	setNonZero(&existing.Name, module.Name)
+	setNonZero(&existing.EpochTime, module.EpochTime)
+	setNonZero(&existing.VersionNumber, module.VersionNumber)
+	setNonZero(&existing.SaveEpochTime, module.SaveEpochTime)
+	setNonZero(&existing.WorkshopSize, module.WorkshopSize)
+	setNonZero(&existing.WorkshopHash, module.WorkshopHash)
+	setNonZero(&existing.Status, module.Status)
+	setNonZero(&existing.GameMode, module.GameMode)
+	setNonZero(&existing.GameType, module.GameType)
+	setNonZero(&existing.GameComplexity, module.GameComplexity)
+	setNonZero(&existing.MinPlayingTime, module.MinPlayingTime)
+	setNonZero(&existing.MaxPlayingTime, module.MaxPlayingTime)
+	setNonZero(&existing.Table, module.Table)
+	setNonZero(&existing.Sky, module.Sky)
+	setNonZero(&existing.Note, module.Note)
+
+	if !module.WorkshopModTime.IsZero() {
+		existing.WorkshopModTime = module.WorkshopModTime
+	}
+
+	if !module.LastSeenAt.IsZero() {
+		existing.LastSeenAt = module.LastSeenAt
+	}
+
+	if !module.RemovedAt.IsZero() {
+		existing.RemovedAt = module.RemovedAt
+	}
+
+	if !module.SyncedAt.IsZero() {
+		existing.SyncedAt = module.SyncedAt
+	}
+
+	setNonZero(&existing.FailedFiles, module.FailedFiles)
+
+	put(m.s, m.s.data.modules, module.ID, existing)
+
+	return nil
+}
+
+func (m *Module) MarkSeen(ctx context.Context, at time.Time, ids ...uint) error {
+	defer m.s.lock(ctx)()
+
+	for _, id := range ids {
+		existing, ok := m.s.data.modules[id]
+		if !ok {
+			continue
+		}
+
+		existing.Status = service.ModuleStatusActive
+		existing.LastSeenAt = at
+		existing.RemovedAt = time.Time{}
+
+		put(m.s, m.s.data.modules, id, existing)
+	}
+
+	return nil
+}
+
+func (m *Module) MarkRemoved(ctx context.Context, at time.Time, ids ...uint) error {
+	defer m.s.lock(ctx)()
+
+	for _, id := range ids {
+		existing, ok := m.s.data.modules[id]
+		if !ok || existing.Status != service.ModuleStatusActive {
+			continue
+		}
+
+		existing.Status = service.ModuleStatusRemoved
+		existing.RemovedAt = at
+
+		put(m.s, m.s.data.modules, id, existing)
+	}
+
+	return nil
+}
+
+func (m *Module) UpdateStatus(ctx context.Context, id uint, status service.ModuleStatus) error {
+	defer m.s.lock(ctx)()
+
+	existing, ok := m.s.data.modules[id]
+	if !ok {
+		return service.ErrModuleIsNotFound
+	}
+
+	existing.Status = status
+	put(m.s, m.s.data.modules, id, existing)
+
+	return nil
+}
+
+// withDefaultStatus makes modules stored without a status active, like the
+// column default of the SQL storage does. Tags and player counts are copied,
+// sorted like the SQL storage returns them.
+func withDefaultStatus(module service.Module) service.Module {
+	if module.Status == "" {
+		module.Status = service.ModuleStatusActive
+	}
+
+	module.Tags = slices.Compact(slices.Sorted(slices.Values(module.Tags)))
+	module.PlayerCounts = slices.Compact(slices.Sorted(slices.Values(module.PlayerCounts)))
+
+	return module
+}
+
+func setNonZero[T comparable](dst *T, v T) {
+	var zero T
+
+	if v != zero {
+		*dst = v
+	}
+}
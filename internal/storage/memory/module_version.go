@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+var _ service.ModuleVersionRepository = (*ModuleVersion)(nil)
+
+type ModuleVersion struct {
+	s *Storage
+}
+
+func (mv *ModuleVersion) Get(ctx context.Context, id uint) (*service.ModuleVersion, error) {
+	defer mv.s.lock(ctx)()
+
+	existing, ok := mv.s.data.versions[id]
+	if !ok {
+		return nil, service.ErrModuleVersionIsNotFound
+	}
+
+	return &existing, nil
+}
+
+func (mv *ModuleVersion) ListByModuleID(ctx context.Context, moduleID uint) ([]service.ModuleVersion, error) {
+	defer mv.s.lock(ctx)()
+
+	result := make([]service.ModuleVersion, 0, 1)
+
+	for _, v := range sortedValues(mv.s.data.versions) {
+		if v.ModuleID == moduleID {
+			v.Data = nil
+			result = append(result, v)
+		}
+	}
+
+	return result, nil
+}
+
+func (mv *ModuleVersion) Create(ctx context.Context, version *service.ModuleVersion) error {
+	defer mv.s.lock(ctx)()
+
+	for _, v := range mv.s.data.versions {
+		if v.ModuleID == version.ModuleID && v.Hash == version.Hash {
+			return nil
+		}
+	}
+
+	version.ID = mv.s.data.nextID("module_versions")
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+
+	put(mv.s, mv.s.data.versions, version.ID, *version)
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+var _ service.RunRepository = (*Run)(nil)
+
+type Run struct {
+	s *Storage
+}
+
+func (r *Run) Create(ctx context.Context, run *service.Run) error {
+	defer r.s.lock(ctx)()
+
+	run.ID = r.s.data.nextID("runs")
+	put(r.s, r.s.data.runs, run.ID, cloneRun(*run))
+
+	return nil
+}
+
+func (r *Run) Update(ctx context.Context, run *service.Run) error {
+	defer r.s.lock(ctx)()
+
+	if _, ok := r.s.data.runs[run.ID]; !ok {
+		return service.ErrRunIsNotFound
+	}
+
+	put(r.s, r.s.data.runs, run.ID, cloneRun(*run))
+
+	return nil
+}
+
+func (r *Run) Get(ctx context.Context, id uint) (*service.Run, error) {
+	defer r.s.lock(ctx)()
+
+	existing, ok := r.s.data.runs[id]
+	if !ok {
+		return nil, service.ErrRunIsNotFound
+	}
+
+	existing = cloneRun(existing)
+
+	return &existing, nil
+}
+
+func (r *Run) List(ctx context.Context, limit int) ([]service.Run, error) {
+	defer r.s.lock(ctx)()
+
+	result := sortedValues(r.s.data.runs)
+	slices.Reverse(result)
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	for i := range result {
+		result[i] = cloneRun(result[i])
+	}
+
+	return result, nil
+}
+
+func (r *Run) AddModules(ctx context.Context, modules ...service.RunModule) error {
+	defer r.s.lock(ctx)()
+
+	for _, m := range modules {
+		m.ID = r.s.data.nextID("run_modules")
+		put(r.s, r.s.data.runModules, m.ID, m)
+	}
+
+	return nil
+}
+
+func (r *Run) ListModules(ctx context.Context, runID uint) ([]service.RunModule, error) {
+	defer r.s.lock(ctx)()
+
+	result := make([]service.RunModule, 0, 1)
+
+	for _, m := range sortedValues(r.s.data.runModules) {
+		if m.RunID == runID {
+			result = append(result, m)
+		}
+	}
+
+	return result, nil
+}
+
+// cloneRun keeps callers from sharing the module list with the storage.
+func cloneRun(run service.Run) service.Run {
+	run.Modules = slices.Clone(run.Modules)
+
+	return run
+}
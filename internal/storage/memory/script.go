@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+var _ service.ScriptRepository = (*Script)(nil)
+
+type Script struct {
+	s *Storage
+}
+
+func (sc *Script) Upsert(ctx context.Context, scripts ...*service.Script) error {
+	defer sc.s.lock(ctx)()
+
+	byHash := make(map[string]uint, len(sc.s.data.scripts))
+	for id, existing := range sc.s.data.scripts {
+		byHash[existing.Hash] = id
+	}
+
+	for _, script := range scripts {
+		if id, ok := byHash[script.Hash]; ok {
+			script.ID = id
+			continue
+		}
+
+		script.ID = sc.s.data.nextID("scripts")
+		put(sc.s, sc.s.data.scripts, script.ID, *script)
+		byHash[script.Hash] = script.ID
+	}
+
+	return nil
+}
+
+func (sc *Script) BatchCreateIncludes(ctx context.Context, includes ...service.ScriptInclude) error {
+	defer sc.s.lock(ctx)()
+
+	for _, include := range includes {
+		exists := false
+
+		for _, existing := range sc.s.data.includes {
+			if existing.BundleID == include.BundleID && existing.Name == include.Name {
+				exists = true
+				break
+			}
+		}
+
+		if exists {
+			continue
+		}
+
+		include.ID = sc.s.data.nextID("script_includes")
+		put(sc.s, sc.s.data.includes, include.ID, include)
+	}
+
+	return nil
+}
+
+func (sc *Script) ReplaceUsages(ctx context.Context, moduleID uint, usages ...service.ScriptUsage) error {
+	defer sc.s.lock(ctx)()
+
+	for id, existing := range sc.s.data.usages {
+		if existing.ModuleID == moduleID {
+			remove(sc.s, sc.s.data.usages, id)
+		}
+	}
+
+	for _, usage := range usages {
+		usage.ID = sc.s.data.nextID("script_usages")
+		put(sc.s, sc.s.data.usages, usage.ID, usage)
+	}
+
+	return nil
+}
+
+// Search matches content case-insensitively, like LIKE does in SQLite.
+func (sc *Script) Search(ctx context.Context, text string) ([]service.ScriptMatch, error) {
+	defer sc.s.lock(ctx)()
+
+	text = strings.ToLower(text)
+
+	matched := make(map[uint]struct{})
+
+	for id, script := range sc.s.data.scripts {
+		if strings.Contains(strings.ToLower(script.Content), text) {
+			matched[id] = struct{}{}
+		}
+	}
+
+	bundles := make(map[uint]struct{})
+
+	for _, include := range sc.s.data.includes {
+		if _, ok := matched[include.ScriptID]; ok {
+			bundles[include.BundleID] = struct{}{}
+		}
+	}
+
+	maps.Copy(matched, bundles)
+
+	result := make([]service.ScriptMatch, 0, 1)
+
+	for _, usage := range sortedValues(sc.s.data.usages) {
+		if _, ok := matched[usage.ScriptID]; !ok {
+			continue
+		}
+
+		script := sc.s.data.scripts[usage.ScriptID]
+
+		result = append(result, service.ScriptMatch{
+			ScriptUsage: usage,
+			Hash:        script.Hash,
+			Size:        script.Size,
+		})
+	}
+
+	slices.SortStableFunc(result, func(a, b service.ScriptMatch) int {
+		return cmp.Or(cmp.Compare(a.ModuleID, b.ModuleID), cmp.Compare(a.Path, b.Path))
+	})
+
+	return result, nil
+}
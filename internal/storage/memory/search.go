@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+var _ service.SearchRepository = (*Search)(nil)
+
+type Search struct {
+	s *Storage
+}
+
+func (se *Search) ReplaceModule(ctx context.Context, moduleID uint, docs ...service.SearchDocument) error {
+	defer se.s.lock(ctx)()
+
+	for id, existing := range se.s.data.docs {
+		if existing.ModuleID == moduleID {
+			remove(se.s, se.s.data.docs, id)
+		}
+	}
+
+	for _, doc := range docs {
+		doc.ID = se.s.data.nextID("search_documents")
+		doc.ModuleID = moduleID
+		put(se.s, se.s.data.docs, doc.ID, doc)
+	}
+
+	return nil
+}
+
+// Search matches documents containing every word, titles rank ten times
+// higher than bodies.
+func (se *Search) Search(ctx context.Context, query string, limit int) ([]service.SearchHit, error) {
+	defer se.s.lock(ctx)()
+
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	result := make([]service.SearchHit, 0, 1)
+
+	for _, doc := range sortedValues(se.s.data.docs) {
+		title, body := strings.ToLower(doc.Title), strings.ToLower(doc.Body)
+
+		var rank float64
+
+		matched := true
+
+		for _, w := range words {
+			inTitle, inBody := strings.Contains(title, w), strings.Contains(body, w)
+			if !inTitle && !inBody {
+				matched = false
+				break
+			}
+
+			if inTitle {
+				rank += 10
+			}
+
+			if inBody {
+				rank++
+			}
+		}
+
+		if matched {
+			result = append(result, service.SearchHit{SearchDocument: doc, Rank: rank})
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b service.SearchHit) int {
+		return cmp.Compare(b.Rank, a.Rank)
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,212 @@
+// Package memory keeps the storage in maps, for tests and dry runs. Nothing
+// is persisted.
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	service "github.com/ldmonster/tts-parser/internal"
+)
+
+var _ service.Storage = (*Storage)(nil)
+
+type Storage struct {
+	mu sync.Mutex
+
+	data data
+	// undo reverts the changes of the running transaction, newest last. It is
+	// nil outside of transactions.
+	undo []func()
+
+	module        *Module
+	file          *File
+	fileFailure   *FileFailure
+	moduleVersion *ModuleVersion
+	script        *Script
+	search        *Search
+	run           *Run
+}
+
+type data struct {
+	modules  map[uint]service.Module
+	files    map[uint]service.File
+	failures map[uint]service.FileFailure
+	versions map[uint]service.ModuleVersion
+	scripts  map[uint]service.Script
+	usages   map[uint]service.ScriptUsage
+	includes map[uint]service.ScriptInclude
+	docs     map[uint]service.SearchDocument
+
+	runs       map[uint]service.Run
+	runModules map[uint]service.RunModule
+
+	// lastID is the last ID assigned per table, IDs are never reused.
+	lastID map[string]uint
+}
+
+func NewStorage() *Storage {
+	s := &Storage{
+		data: data{
+			modules:    make(map[uint]service.Module),
+			files:      make(map[uint]service.File),
+			failures:   make(map[uint]service.FileFailure),
+			versions:   make(map[uint]service.ModuleVersion),
+			scripts:    make(map[uint]service.Script),
+			usages:     make(map[uint]service.ScriptUsage),
+			includes:   make(map[uint]service.ScriptInclude),
+			docs:       make(map[uint]service.SearchDocument),
+			runs:       make(map[uint]service.Run),
+			runModules: make(map[uint]service.RunModule),
+			lastID:     make(map[string]uint),
+		},
+	}
+
+	s.module = &Module{s: s}
+	s.file = &File{s: s}
+	s.fileFailure = &FileFailure{s: s}
+	s.moduleVersion = &ModuleVersion{s: s}
+	s.script = &Script{s: s}
+	s.search = &Search{s: s}
+	s.run = &Run{s: s}
+
+	return s
+}
+
+func (s *Storage) Modules() service.ModuleRepository {
+	return s.module
+}
+
+func (s *Storage) Files() service.FileRepository {
+	return s.file
+}
+
+func (s *Storage) FileFailures() service.FileFailureRepository {
+	return s.fileFailure
+}
+
+func (s *Storage) ModuleVersions() service.ModuleVersionRepository {
+	return s.moduleVersion
+}
+
+func (s *Storage) Scripts() service.ScriptRepository {
+	return s.script
+}
+
+func (s *Storage) Search() service.SearchRepository {
+	return s.search
+}
+
+func (s *Storage) Runs() service.RunRepository {
+	return s.run
+}
+
+func (s *Storage) Migrate(_ context.Context) error {
+	return nil
+}
+
+// txKey marks contexts of a running transaction of the storage.
+type txKey struct{}
+
+// Transaction runs f holding the storage lock, other callers wait until it
+// ends. Changes made by f are reverted when it fails, only the rows f changed
+// are touched. Assigned IDs are not reverted.
+func (s *Storage) Transaction(ctx context.Context, f func(context.Context) error) error {
+	if !s.inTransaction(ctx) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.undo = make([]func(), 0)
+		defer func() { s.undo = nil }()
+
+		ctx = context.WithValue(ctx, txKey{}, s)
+	}
+
+	// nested transactions revert their own changes only
+	mark := len(s.undo)
+
+	err := f(ctx)
+	if err != nil {
+		for i := len(s.undo) - 1; i >= mark; i-- {
+			s.undo[i]()
+		}
+
+		s.undo = s.undo[:mark]
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *Storage) inTransaction(ctx context.Context) bool {
+	tx, _ := ctx.Value(txKey{}).(*Storage)
+
+	return tx == s
+}
+
+// lock locks the storage unless ctx belongs to a transaction holding the lock
+// already, and returns the matching unlock.
+func (s *Storage) lock(ctx context.Context) func() {
+	if s.inTransaction(ctx) {
+		return func() {}
+	}
+
+	s.mu.Lock()
+
+	return s.mu.Unlock
+}
+
+func (d *data) nextID(table string) uint {
+	d.lastID[table]++
+
+	return d.lastID[table]
+}
+
+// put stores v under id in m, the table of s, recording the previous row when
+// a transaction runs.
+func put[V any](s *Storage, m map[uint]V, id uint, v V) {
+	record(s, m, id)
+	m[id] = v
+}
+
+// remove deletes id from m, the table of s, recording the previous row when a
+// transaction runs.
+func remove[V any](s *Storage, m map[uint]V, id uint) {
+	if _, ok := m[id]; !ok {
+		return
+	}
+
+	record(s, m, id)
+	delete(m, id)
+}
+
+func record[V any](s *Storage, m map[uint]V, id uint) {
+	if s.undo == nil {
+		return
+	}
+
+	old, existed := m[id]
+
+	s.undo = append(s.undo, func() {
+		if existed {
+			m[id] = old
+		} else {
+			delete(m, id)
+		}
+	})
+}
+
+// sortedValues returns values of m ordered by their keys, the IDs.
+func sortedValues[V any](m map[uint]V) []V {
+	keys := slices.Sorted(maps.Keys(m))
+
+	result := make([]V, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, m[k])
+	}
+
+	return result
+}
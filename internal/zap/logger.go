@@ -52,7 +52,9 @@ func NewProductionZaplogger(filename string, lvl zap.AtomicLevel) (*zap.Logger,
 	conf.DisableCaller = true
 	conf.DisableStacktrace = true
 
-	zapLogger, err := conf.Build(zap.WrapCore(zapCore))
+	zapLogger, err := conf.Build(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapCore(c, filename)
+	}))
 	if err != nil {
 		return nil, fmt.Errorf("build: %w", err)
 	}
@@ -60,11 +62,12 @@ func NewProductionZaplogger(filename string, lvl zap.AtomicLevel) (*zap.Logger,
 	return zapLogger, nil
 }
 
-func zapCore(c zapcore.Core) zapcore.Core {
+// zapCore tees c into a rotated JSON file of every level.
+func zapCore(c zapcore.Core, filename string) zapcore.Core {
 	// lumberjack.Logger is already safe for concurrent use, so we don't need to
 	// lock it.
 	w := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   "./logs/chat.log",
+		Filename:   filename,
 		MaxSize:    2, // megabytes
 		MaxBackups: 30,
 		MaxAge:     28, // days